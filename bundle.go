@@ -0,0 +1,135 @@
+package jsonschema
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Bundle returns a self-contained copy of s, in which every schema that a
+// $ref points to in another document is copied once into Definitions, and
+// every $ref is rewritten to a local reference such as "#/definitions/Money".
+// Documents referenced by s are loaded with loader.
+//
+// Definitions copied from other documents are named after the last token of
+// the reference, or after the referenced file when the reference is to a whole
+// document, with a number appended if the name is already taken. Identifiers
+// ($id and id) of schemas other than the root are removed, as they would
+// otherwise change the meaning of the rewritten references.
+func Bundle(s *Schema, loader Loader) (*Schema, error) {
+	b := &bundler{
+		resolver:    NewResolver(loader),
+		root:        s.Type,
+		pointers:    map[*Type]string{},
+		names:       map[*Type]string{},
+		definitions: Definitions{},
+	}
+	if err := b.resolver.AddSchema("", s); err != nil {
+		return nil, err
+	}
+	if s.Type != nil {
+		b.index(s.Type, "")
+	}
+	for _, name := range sortedKeys(s.Definitions) {
+		b.index(s.Definitions[name], "/definitions/"+escapePointerToken(name))
+		b.definitions[name] = nil
+	}
+
+	bundled := &Schema{Definitions: b.definitions}
+	if s.Type != nil {
+		bundled.Type = s.Type.clone(b.rewrite)
+	}
+	// Definitions are copied in order, as names are given out in the order
+	// references are found.
+	for _, name := range sortedKeys(s.Definitions) {
+		b.definitions[name] = s.Definitions[name].clone(b.rewrite)
+	}
+	for len(b.pending) > 0 {
+		target := b.pending[0]
+		b.pending = b.pending[1:]
+		b.definitions[b.names[target]] = target.clone(b.rewrite)
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return bundled, nil
+}
+
+type bundler struct {
+	resolver    *Resolver
+	root        *Type
+	pointers    map[*Type]string // JSON Pointers of the schemas in the root document
+	names       map[*Type]string // definition names of the schemas copied from other documents
+	definitions Definitions
+	pending     []*Type
+	err         error
+}
+
+// index records the JSON Pointer of t and of its subschemas.
+func (b *bundler) index(t *Type, pointer string) {
+	if _, ok := b.pointers[t]; ok {
+		return
+	}
+	b.pointers[t] = pointer
+	t.eachSubschema(func(tokens []string, sub *Type) {
+		p := pointer
+		for _, token := range tokens {
+			p += "/" + escapePointerToken(token)
+		}
+		b.index(sub, p)
+	})
+}
+
+// rewrite rewrites the $ref of the copy of orig to point into the bundle.
+func (b *bundler) rewrite(orig, copy *Type) {
+	if orig != b.root {
+		copy.ID = ""
+		delete(copy.Extras, "id")
+	}
+	if orig.Ref == "" || b.err != nil {
+		return
+	}
+	base := b.resolver.Base(orig)
+	target, _, err := b.resolver.Resolve(base, orig.Ref)
+	if err != nil {
+		b.err = err
+		return
+	}
+	if pointer, ok := b.pointers[target]; ok {
+		copy.Ref = "#" + pointer
+		return
+	}
+	name, ok := b.names[target]
+	if !ok {
+		uri, _ := resolveURI(base, orig.Ref)
+		name = b.name(uri)
+		b.names[target] = name
+		b.definitions[name] = nil
+		b.pending = append(b.pending, target)
+	}
+	copy.Ref = "#/definitions/" + escapePointerToken(name)
+}
+
+// name returns an unused definition name for the schema at uri.
+func (b *bundler) name(uri string) string {
+	docURI, fragment := splitFragment(uri)
+	name := fragment
+	if strings.HasPrefix(fragment, "/") {
+		tokens := strings.Split(fragment, "/")
+		name = unescapePointerToken(tokens[len(tokens)-1])
+	}
+	if name == "" {
+		base := path.Base(docURI)
+		name = strings.TrimSuffix(base, path.Ext(base))
+	}
+	if name == "" || name == "." || name == "/" {
+		name = "schema"
+	}
+	candidate := name
+	for i := 2; ; i++ {
+		if _, taken := b.definitions[candidate]; !taken {
+			return candidate
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+}
@@ -0,0 +1,91 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle(t *testing.T) {
+	loader := &FSLoader{FS: os.DirFS("fixtures/refs")}
+	root, err := loader.Load("order.json")
+	require.NoError(t, err)
+
+	bundled, err := Bundle(root, loader)
+	require.NoError(t, err)
+
+	expected, err := ioutil.ReadFile("fixtures/bundled.json")
+	require.NoError(t, err)
+	actual, err := json.MarshalIndent(bundled, "", "  ")
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(actual))
+
+	// The bundle resolves without a loader.
+	r := NewResolver(nil)
+	require.NoError(t, r.AddSchema("", bundled))
+	for _, def := range bundled.Definitions {
+		_, err := r.Deref(def)
+		require.NoError(t, err)
+	}
+}
+
+func TestBundleNameCollision(t *testing.T) {
+	loader := MemoryLoader{
+		"a.json": mustSchema(t, `{"definitions": {"Name": {"type": "string"}}}`),
+		"b.json": mustSchema(t, `{"definitions": {"Name": {"type": "integer"}}}`),
+	}
+	root := mustSchema(t, `{
+		"properties": {
+			"a": {"$ref": "a.json#/definitions/Name"},
+			"b": {"$ref": "b.json#/definitions/Name"},
+			"c": {"$ref": "a.json#/definitions/Name"}
+		},
+		"definitions": {"Name": {"type": "boolean"}}
+	}`)
+	bundled, err := Bundle(root, loader)
+	require.NoError(t, err)
+
+	actual, err := json.Marshal(bundled)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"properties": {
+			"a": {"$ref": "#/definitions/Name2"},
+			"b": {"$ref": "#/definitions/Name3"},
+			"c": {"$ref": "#/definitions/Name2"}
+		},
+		"definitions": {
+			"Name": {"type": "boolean"},
+			"Name2": {"type": "string"},
+			"Name3": {"type": "integer"}
+		}
+	}`, string(actual))
+}
+
+func TestBundleDeterministic(t *testing.T) {
+	loader := MemoryLoader{}
+	definitions := map[string]interface{}{}
+	for _, doc := range []string{"a", "b", "c", "d", "e", "f"} {
+		loader[doc+".json"] = mustSchema(t, `{"definitions": {"Money": {"description": "`+doc+`"}}}`)
+		definitions[doc] = map[string]interface{}{"$ref": doc + ".json#/definitions/Money"}
+	}
+	data, err := json.Marshal(map[string]interface{}{"definitions": definitions})
+	require.NoError(t, err)
+	root := mustSchema(t, string(data))
+
+	var first []byte
+	for i := 0; i < 20; i++ {
+		bundled, err := Bundle(root, loader)
+		require.NoError(t, err)
+		actual, err := json.Marshal(bundled)
+		require.NoError(t, err)
+		if first == nil {
+			first = actual
+			require.Contains(t, string(actual), `"a":{"$ref":"#/definitions/Money"}`)
+			require.Contains(t, string(actual), `"f":{"$ref":"#/definitions/Money6"}`)
+		}
+		require.Equal(t, string(first), string(actual))
+	}
+}
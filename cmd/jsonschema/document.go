@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A document is a JSON or YAML document decoded to JSON values, with the
+// positions of the values in the source.
+type document struct {
+	value     interface{}
+	positions map[string]position // by JSON Pointer
+}
+
+// position is a line and column in a source file, both starting at 1.
+type position struct {
+	line, column int
+}
+
+// positionError is an error decoding a document at a known position.
+type positionError struct {
+	position
+	err error
+}
+
+func (e *positionError) Error() string {
+	return e.err.Error()
+}
+
+// position returns the position of the value at pointer, or of its closest
+// parent with a known position.
+func (d *document) position(pointer string) position {
+	for {
+		if p, ok := d.positions[pointer]; ok {
+			return p
+		}
+		i := strings.LastIndexByte(pointer, '/')
+		if i < 0 {
+			return position{1, 1}
+		}
+		pointer = pointer[:i]
+	}
+}
+
+// isYAML reports whether the file name has a YAML extension.
+func isYAML(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".yaml") || strings.HasSuffix(lower, ".yml")
+}
+
+// parseDocuments decodes the documents in a file, of which a YAML file may
+// have several.
+func parseDocuments(name string, data []byte) ([]*document, error) {
+	if isYAML(name) {
+		return parseYAML(data)
+	}
+	d, err := parseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*document{d}, nil
+}
+
+// parseJSON decodes a JSON document.
+func parseJSON(data []byte) (*document, error) {
+	p := &jsonParser{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	p.dec.UseNumber()
+	d := &document{positions: map[string]position{}}
+	value, err := p.value(d, "")
+	if err == nil {
+		if _, err = p.dec.Token(); err == io.EOF {
+			d.value = value
+			return d, nil
+		} else if err == nil {
+			err = errors.New("invalid character after top-level value")
+		}
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return nil, &positionError{p.position(int(syntaxErr.Offset)), err}
+	}
+	return nil, &positionError{p.position(int(p.dec.InputOffset())), err}
+}
+
+type jsonParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// value decodes the next value, recording the positions of it and its
+// children.
+func (p *jsonParser) value(d *document, pointer string) (interface{}, error) {
+	start := int(p.dec.InputOffset())
+	for start < len(p.data) && strings.IndexByte(" \t\r\n,:", p.data[start]) >= 0 {
+		start++
+	}
+	d.positions[pointer] = p.position(start)
+	token, err := p.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := map[string]interface{}{}
+		for p.dec.More() {
+			key, err := p.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			name := key.(string)
+			if object[name], err = p.value(d, pointer+"/"+escapePointerToken(name)); err != nil {
+				return nil, err
+			}
+		}
+		_, err = p.dec.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for p.dec.More() {
+			item, err := p.value(d, pointer+"/"+strconv.Itoa(len(array)))
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+		_, err = p.dec.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// position returns the position of a byte offset.
+func (p *jsonParser) position(offset int) position {
+	if offset > len(p.data) {
+		offset = len(p.data)
+	}
+	line := bytes.Count(p.data[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(p.data[:offset], '\n')
+	return position{line, column}
+}
+
+// parseYAML decodes the documents of a YAML stream.
+func parseYAML(data []byte) ([]*document, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var documents []*document
+	for {
+		var node yaml.Node
+		if err := dec.Decode(&node); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		p := &yamlParser{d: &document{positions: map[string]position{}}}
+		value, err := p.value(&node, "")
+		if err != nil {
+			return nil, err
+		}
+		p.d.value = value
+		documents = append(documents, p.d)
+	}
+	if len(documents) == 0 {
+		return []*document{{positions: map[string]position{}}}, nil
+	}
+	return documents, nil
+}
+
+// maxAliasedValues limits the number of values that the aliases of a YAML
+// document may expand to, so that nested aliases cannot exhaust memory.
+const maxAliasedValues = 100000
+
+type yamlParser struct {
+	d       *document
+	aliases int // depth of aliases being expanded
+	aliased int // number of values expanded from aliases
+}
+
+// value converts a YAML node to a JSON value, recording the positions of it
+// and its children.
+func (p *yamlParser) value(n *yaml.Node, pointer string) (interface{}, error) {
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return p.value(n.Content[0], pointer)
+	}
+	d := p.d
+	d.positions[pointer] = position{n.Line, n.Column}
+	fail := func(format string, args ...interface{}) error {
+		return &positionError{position{n.Line, n.Column}, fmt.Errorf(format, args...)}
+	}
+	if p.aliases > 0 {
+		if p.aliased++; p.aliased > maxAliasedValues {
+			return nil, fail("aliases expand to more than %d values", maxAliasedValues)
+		}
+	}
+
+	switch n.Kind {
+	case yaml.AliasNode:
+		p.aliases++
+		defer func() { p.aliases-- }()
+		return p.value(n.Alias, pointer)
+	case yaml.SequenceNode:
+		array := make([]interface{}, len(n.Content))
+		for i, item := range n.Content {
+			value, err := p.value(item, pointer+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			array[i] = value
+		}
+		return array, nil
+	case yaml.MappingNode:
+		object := map[string]interface{}{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fail("mapping keys must be strings")
+			}
+			if key.Tag == "!!merge" {
+				merged, err := p.value(value, pointer)
+				if err != nil {
+					return nil, err
+				}
+				if err := merge(object, merged); err != nil {
+					return nil, fail("%s", err)
+				}
+				continue
+			}
+			v, err := p.value(value, pointer+"/"+escapePointerToken(key.Value))
+			if err != nil {
+				return nil, err
+			}
+			object[key.Value] = v
+		}
+		d.positions[pointer] = position{n.Line, n.Column}
+		return object, nil
+	}
+
+	var value interface{}
+	if err := n.Decode(&value); err != nil {
+		return nil, fail("%s", err)
+	}
+	switch v := value.(type) {
+	case int:
+		return json.Number(strconv.Itoa(v)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10)), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fail("%s is not a JSON number", n.Value)
+		}
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64)), nil
+	case time.Time:
+		return n.Value, nil
+	case nil, bool, string:
+		return v, nil
+	}
+	return n.Value, nil
+}
+
+// merge merges the mappings of a YAML merge key into object, without
+// overriding the keys it already has.
+func merge(object map[string]interface{}, merged interface{}) error {
+	var mappings []interface{}
+	switch m := merged.(type) {
+	case map[string]interface{}:
+		mappings = []interface{}{m}
+	case []interface{}:
+		mappings = m
+	default:
+		return errors.New("merge key value must be a mapping or a sequence of mappings")
+	}
+	for _, mapping := range mappings {
+		m, ok := mapping.(map[string]interface{})
+		if !ok {
+			return errors.New("merge key value must be a mapping or a sequence of mappings")
+		}
+		for key, value := range m {
+			if _, ok := object[key]; !ok {
+				object[key] = value
+			}
+		}
+	}
+	return nil
+}
+
+func escapePointerToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,314 @@
+// Command jsonschema writes JSON Schemas reflected from the types of a Go
+// package in the current module, and validates JSON and YAML files against
+// schemas.
+//
+// The reflect command, which is the default, is meant for go:generate:
+//
+//	//go:generate go run github.com/alecthomas/jsonschema/cmd/jsonschema -pkg ./api -type User,Order -out schemas/
+//
+// The package is loaded by generating a small program that imports it and
+// running it with "go run" from within the module, so the module must require
+// github.com/alecthomas/jsonschema.
+//
+// When -out names a directory, ending in a slash or already existing, one
+// schema is written for each type, to a file named after the type in lower
+// case. Otherwise a single document is written, to -out or to standard output,
+// whose definitions include every type. With -yaml, schemas are written in
+// YAML rather than JSON, and with -yaml-tags properties are named after yaml
+// tags, for the schemas of YAML documents.
+//
+// The validate command validates each file, in JSON or in YAML, against a
+// schema, which may itself be in either, and reports each failure as
+// "file:line:col: /path: message", or as JSON with -json:
+//
+//	jsonschema validate -schema config.schema.json configs/*.yaml
+//
+// References to other schemas are loaded from the directory of the schema.
+// The exit status is 1 if a file is not valid, and 2 on other errors.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/alecthomas/jsonschema"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if errors.Is(err, errInvalid) {
+			os.Exit(1)
+		}
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "jsonschema: %s\n", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run runs the command named by the first argument, or the reflect command.
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "reflect":
+			return reflectCommand(args[1:], stdout, stderr)
+		case "validate":
+			return validateCommand(args[1:], stdout, stderr)
+		}
+	}
+	return reflectCommand(args, stdout, stderr)
+}
+
+// options are the command-line flags.
+type options struct {
+	pkg   string
+	types []string
+	out   string
+	yaml  bool
+
+	allowAdditionalProperties  bool
+	requiredFromJSONSchemaTags bool
+	expandedStruct             bool
+	propertyOrder              bool
+	inlineRefs                 bool
+	preferYAMLTags             bool
+}
+
+func reflectCommand(args []string, stdout, stderr io.Writer) error {
+	opts := &options{}
+	flags := flag.NewFlagSet("jsonschema reflect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.pkg, "pkg", ".", "package containing the types, as a directory or import path")
+	types := flags.String("type", "", "comma-separated names of the types to reflect")
+	flags.StringVar(&opts.out, "out", "", "output file, or directory for one file per type (default standard output)")
+	flags.BoolVar(&opts.yaml, "yaml", false, "write schemas as YAML rather than JSON")
+	flags.BoolVar(&opts.allowAdditionalProperties, "allow-additional-properties", false, "allow properties not defined by structs")
+	flags.BoolVar(&opts.requiredFromJSONSchemaTags, "required-from-jsonschema-tags", false, "take required properties from jsonschema tags rather than json tags")
+	flags.BoolVar(&opts.expandedStruct, "expanded-struct", false, "put the properties of each type at the root rather than in a definition")
+	flags.BoolVar(&opts.propertyOrder, "property-order", false, "add propertyOrder to each property")
+	flags.BoolVar(&opts.inlineRefs, "inline-refs", false, "inline references to definitions")
+	flags.BoolVar(&opts.preferYAMLTags, "yaml-tags", false, "name properties after yaml tags rather than json tags")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+	for _, name := range strings.Split(*types, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			if !token.IsIdentifier(name) {
+				return fmt.Errorf("invalid type name %q", name)
+			}
+			opts.types = append(opts.types, name)
+		}
+	}
+	if len(opts.types) == 0 {
+		return errors.New("-type is required")
+	}
+
+	schemas, err := reflectPackage(opts, stderr)
+	if err != nil {
+		return err
+	}
+
+	if isDir(opts.out) {
+		if err := os.MkdirAll(opts.out, 0777); err != nil {
+			return err
+		}
+		ext := ".json"
+		if opts.yaml {
+			ext = ".yaml"
+		}
+		for _, name := range opts.types {
+			path := filepath.Join(opts.out, strings.ToLower(name)+ext)
+			if err := writeSchema(path, stdout, schemas[name], opts.yaml); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(opts.types) == 1 {
+		return writeSchema(opts.out, stdout, schemas[opts.types[0]], opts.yaml)
+	}
+	return writeSchema(opts.out, stdout, combine(opts.types, schemas), opts.yaml)
+}
+
+// isDir reports whether out names a directory.
+func isDir(out string) bool {
+	if out == "" {
+		return false
+	}
+	if strings.HasSuffix(out, "/") || strings.HasSuffix(out, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(out)
+	return err == nil && info.IsDir()
+}
+
+// combine returns a document whose definitions include the schemas of every
+// type.
+func combine(types []string, schemas map[string]*jsonschema.Schema) *jsonschema.Schema {
+	combined := &jsonschema.Schema{
+		Type:        &jsonschema.Type{Version: jsonschema.Version},
+		Definitions: jsonschema.Definitions{},
+	}
+	for _, name := range types {
+		s := schemas[name]
+		for defName, def := range s.Definitions {
+			combined.Definitions[defName] = def
+		}
+		if s.Type != nil && s.Type.Ref != "#/definitions/"+name {
+			s.Type.Version = ""
+			combined.Definitions[name] = s.Type
+		}
+	}
+	return combined
+}
+
+// writeSchema writes s to path, or to stdout if path is empty, in JSON or in
+// YAML.
+func writeSchema(path string, stdout io.Writer, s *jsonschema.Schema, asYAML bool) error {
+	data, err := encodeSchema(s, asYAML)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0666)
+}
+
+// encodeSchema returns the indented JSON or YAML encoding of s.
+func encodeSchema(s *jsonschema.Schema, asYAML bool) ([]byte, error) {
+	if !asYAML {
+		data, err := json.MarshalIndent(s, "", "  ")
+		return append(data, '\n'), err
+	}
+	return jsonschema.EncodeYAML(s)
+}
+
+// reflectPackage returns the schemas of the types of the package, by
+// generating a program that reflects them and running it within the module of
+// the package.
+func reflectPackage(opts *options, stderr io.Writer) (map[string]*jsonschema.Schema, error) {
+	var pkg struct {
+		ImportPath string
+		Name       string
+		Module     *struct{ Dir string }
+	}
+	out, err := goCommand("", stderr, "list", "-json", opts.pkg)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(out))
+	if err := dec.Decode(&pkg); err != nil {
+		return nil, fmt.Errorf("go list %s: %w", opts.pkg, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("-pkg %s matches several packages, but must name exactly one", opts.pkg)
+	}
+	if pkg.Name == "main" {
+		return nil, fmt.Errorf("%s is a main package, which cannot be imported", pkg.ImportPath)
+	}
+	if pkg.Module == nil {
+		return nil, fmt.Errorf("%s is not in a module", pkg.ImportPath)
+	}
+
+	dir, err := ioutil.TempDir(pkg.Module.Dir, "jsonschema-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	src := &bytes.Buffer{}
+	if err := helper.Execute(src, map[string]interface{}{
+		"ImportPath": pkg.ImportPath,
+		"Types":      opts.types,
+		"Reflector": map[string]bool{
+			"AllowAdditionalProperties":  opts.allowAdditionalProperties,
+			"RequiredFromJSONSchemaTags": opts.requiredFromJSONSchemaTags,
+			"ExpandedStruct":             opts.expandedStruct,
+			"PropertyOrder":              opts.propertyOrder,
+			"InlineRefs":                 opts.inlineRefs,
+			"PreferYAMLTags":             opts.preferYAMLTags,
+		},
+	}); err != nil {
+		return nil, err
+	}
+	main := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(main, src.Bytes(), 0666); err != nil {
+		return nil, err
+	}
+	out, err = goCommand(pkg.Module.Dir, stderr, "run", main)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, err
+	}
+	schemas := map[string]*jsonschema.Schema{}
+	for name, data := range raw {
+		s := &jsonschema.Schema{}
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		schemas[name] = s
+	}
+	return schemas, nil
+}
+
+// goCommand runs the go command in dir and returns its output.
+func goCommand(dir string, stderr io.Writer, args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+var helper = template.Must(template.New("main.go").Parse(`// Code generated by jsonschema. DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+
+	"github.com/alecthomas/jsonschema"
+
+	pkg {{ printf "%q" .ImportPath }}
+)
+
+func main() {
+	r := &jsonschema.Reflector{
+{{- range $name, $value := .Reflector }}
+		{{ $name }}: {{ $value }},
+{{- end }}
+	}
+	schemas := map[string]*jsonschema.Schema{
+{{- range .Types }}
+		{{ printf "%q" . }}: r.ReflectFromType(reflect.TypeOf((*pkg.{{ . }})(nil)).Elem()),
+{{- end }}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(schemas); err != nil {
+		panic(err)
+	}
+}
+`))
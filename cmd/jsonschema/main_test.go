@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alecthomas/jsonschema"
+)
+
+func TestOneFilePerType(t *testing.T) {
+	out := t.TempDir()
+	err := run([]string{"-pkg", "./testdata/api", "-type", "User,Order", "-out", out + "/"}, ioutil.Discard, ioutil.Discard)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(filepath.Join(out, "order.json"))
+	require.NoError(t, err)
+	s := &jsonschema.Schema{}
+	require.NoError(t, json.Unmarshal(data, s))
+	require.Equal(t, "#/definitions/Order", s.Type.Ref)
+	require.Contains(t, s.Definitions, "User")
+	require.Contains(t, s.Definitions, "Line")
+	require.FileExists(t, filepath.Join(out, "user.json"))
+}
+
+func TestSeveralPackages(t *testing.T) {
+	err := run([]string{"-pkg", "github.com/alecthomas/jsonschema/...", "-type", "User"}, ioutil.Discard, ioutil.Discard)
+	require.EqualError(t, err, "-pkg github.com/alecthomas/jsonschema/... matches several packages, but must name exactly one")
+}
+
+func TestCombined(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	err := run([]string{"-pkg", "./testdata/api", "-type", "User,Line", "-expanded-struct", "-property-order"}, stdout, ioutil.Discard)
+	require.NoError(t, err)
+
+	s := &jsonschema.Schema{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), s))
+	require.Equal(t, jsonschema.Version, s.Type.Version)
+	require.Len(t, s.Definitions, 2)
+	line := s.Definitions["Line"]
+	require.Equal(t, "", line.Version)
+	quantity, ok := line.Properties.Get("quantity")
+	require.True(t, ok)
+	require.Equal(t, 2, quantity.PropertyOrder)
+}
+
+func TestInvalidType(t *testing.T) {
+	err := run([]string{"-type", "User;os.Exit(1)"}, ioutil.Discard, ioutil.Discard)
+	require.EqualError(t, err, `invalid type name "User;os.Exit(1)"`)
+}
+
+func TestYAML(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	err := run([]string{"-pkg", "./testdata/api", "-type", "Settings", "-yaml", "-yaml-tags", "-expanded-struct"}, stdout, ioutil.Discard)
+	require.NoError(t, err)
+	require.Equal(t, `$schema: http://json-schema.org/draft-04/schema#
+required:
+  - listen
+  - max_body_size
+properties:
+  listen:
+    type: string
+  max_body_size:
+    minimum: 1
+    type: integer
+  verbose:
+    type: boolean
+additionalProperties:
+  type: string
+type: object
+`, stdout.String())
+}
@@ -0,0 +1,35 @@
+// Package api is used to test the jsonschema command.
+package api
+
+// User is a user of the API.
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name" jsonschema:"minLength=1"`
+	Email string `json:"email,omitempty" jsonschema:"format=email"`
+}
+
+// Order is an order placed by a user.
+type Order struct {
+	ID    string `json:"id"`
+	Buyer User   `json:"buyer"`
+	Lines []Line `json:"lines"`
+}
+
+// Line is a line of an order.
+type Line struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity" jsonschema:"minimum=1"`
+}
+
+// Settings are the settings of the API, read from a YAML file.
+type Settings struct {
+	Listen  string            `yaml:"listen"`
+	Limits  Limits            `yaml:",inline"`
+	Labels  map[string]string `yaml:",inline"`
+	Verbose bool              `json:"debug" yaml:",omitempty"`
+}
+
+// Limits are the limits of the API.
+type Limits struct {
+	MaxBodySize int `yaml:"max_body_size" jsonschema:"minimum=1"`
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/jsonschema"
+)
+
+// errInvalid is returned by the validate command when a document is not
+// valid, after reporting why.
+var errInvalid = errors.New("invalid documents")
+
+// fileResult is the result of validating a file, as reported in JSON.
+type fileResult struct {
+	File   string      `json:"file"`
+	Valid  bool        `json:"valid"`
+	Errors []fileError `json:"errors,omitempty"`
+}
+
+// fileError is an error found in a file, as reported in JSON. Errors decoding
+// the file have no instance or schema path.
+type fileError struct {
+	Line         int    `json:"line,omitempty"`
+	Column       int    `json:"column,omitempty"`
+	InstancePath string `json:"instancePath,omitempty"`
+	SchemaPath   string `json:"schemaPath,omitempty"`
+	Keyword      string `json:"keyword,omitempty"`
+	Message      string `json:"message"`
+}
+
+func validateCommand(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("jsonschema validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: jsonschema validate -schema file [-json] file...\n")
+		flags.PrintDefaults()
+	}
+	schemaFile := flags.String("schema", "", "schema to validate against, in JSON or YAML")
+	jsonOutput := flags.Bool("json", false, "report the results as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *schemaFile == "" {
+		return errors.New("-schema is required")
+	}
+	files, err := expandFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("no files to validate")
+	}
+
+	resolver, schema, err := loadSchema(*schemaFile)
+	if err != nil {
+		return err
+	}
+	results := make([]fileResult, len(files))
+	valid := true
+	for i, file := range files {
+		results[i] = validateFile(resolver, schema, file)
+		valid = valid && results[i].Valid
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			for _, e := range result.Errors {
+				location := result.File
+				if e.Line > 0 {
+					location += fmt.Sprintf(":%d:%d", e.Line, e.Column)
+				}
+				if e.Keyword != "" {
+					instancePath := e.InstancePath
+					if instancePath == "" {
+						instancePath = "/"
+					}
+					fmt.Fprintf(stdout, "%s: %s: %s\n", location, instancePath, e.Message)
+				} else {
+					fmt.Fprintf(stdout, "%s: %s\n", location, e.Message)
+				}
+			}
+		}
+	}
+	if !valid {
+		return errInvalid
+	}
+	return nil
+}
+
+// expandFiles expands the glob patterns among args that do not name a file,
+// for shells that leave them alone.
+func expandFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if _, err := os.Stat(arg); err == nil || !strings.ContainsAny(arg, "*?[") {
+			files = append(files, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", arg)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// validateFile validates every document in a file.
+func validateFile(resolver *jsonschema.Resolver, schema *jsonschema.Schema, file string) fileResult {
+	result := fileResult{File: file, Valid: true}
+	data, err := ioutil.ReadFile(file)
+	if err == nil {
+		var documents []*document
+		documents, err = parseDocuments(file, data)
+		for _, d := range documents {
+			verr := resolver.Validate(schema.Type, d.value)
+			var errs jsonschema.ValidationErrors
+			if errors.As(verr, &errs) {
+				sort.SliceStable(errs, func(i, j int) bool {
+					pi, pj := d.position(errs[i].InstancePath), d.position(errs[j].InstancePath)
+					return pi.line < pj.line || (pi.line == pj.line && pi.column < pj.column)
+				})
+				for _, e := range errs {
+					p := d.position(e.InstancePath)
+					result.Errors = append(result.Errors, fileError{
+						Line:         p.line,
+						Column:       p.column,
+						InstancePath: e.InstancePath,
+						SchemaPath:   e.SchemaPath,
+						Keyword:      e.Keyword,
+						Message:      e.Message,
+					})
+				}
+			} else if verr != nil {
+				err = verr
+			}
+		}
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, decodeError(err))
+	}
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// decodeError returns the fileError for an error reading or decoding a file.
+func decodeError(err error) fileError {
+	var perr *positionError
+	if errors.As(err, &perr) {
+		return fileError{Line: perr.line, Column: perr.column, Message: perr.Error()}
+	}
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return fileError{Line: line, Column: 1, Message: err.Error()[len(m[0]):]}
+	}
+	return fileError{Message: err.Error()}
+}
+
+// loadSchema loads a schema file, returning a resolver that loads the files it
+// refers to from the same directory.
+func loadSchema(file string) (*jsonschema.Resolver, *jsonschema.Schema, error) {
+	dir := filepath.Dir(file)
+	loader := jsonschema.LoaderFunc(func(uri string) (*jsonschema.Schema, error) {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+		return readSchema(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+	s, err := readSchema(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolver := jsonschema.NewResolver(loader)
+	if err := resolver.AddSchema(filepath.ToSlash(filepath.Base(file)), s); err != nil {
+		return nil, nil, err
+	}
+	return resolver, s, nil
+}
+
+// readSchema reads a schema in JSON or YAML.
+func readSchema(file string) (*jsonschema.Schema, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if isYAML(file) {
+		documents, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, decodeError(err).Message)
+		}
+		if data, err = json.Marshal(documents[0].value); err != nil {
+			return nil, err
+		}
+	}
+	s := &jsonschema.Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return s, nil
+}
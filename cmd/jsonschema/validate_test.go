@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCommand(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	err := run([]string{"validate", "-schema", "testdata/validate/config.schema.yaml",
+		"testdata/validate/valid.json", "testdata/validate/malformed.json", "testdata/validate/invalid.yaml"}, stdout, ioutil.Discard)
+	require.Equal(t, errInvalid, err)
+	lines := strings.SplitN(stdout.String(), "\n", 2)
+	require.True(t, strings.HasPrefix(lines[0], "testdata/validate/malformed.json:3:12: "), lines[0])
+	require.Equal(t, `testdata/validate/invalid.yaml:1:11: /defaults: property "defaults" is not allowed
+testdata/validate/invalid.yaml:2:9: /port: 70000 is greater than 65535
+testdata/validate/invalid.yaml:3:7: /name: string is longer than 3 characters
+testdata/validate/invalid.yaml:5:8: /extra: property "extra" is not allowed
+`, lines[1])
+}
+
+func TestValidateCommandValid(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	err := run([]string{"validate", "-schema", "testdata/validate/config.schema.yaml", "testdata/validate/valid*.json"}, stdout, ioutil.Discard)
+	require.NoError(t, err)
+	require.Empty(t, stdout.String())
+}
+
+func TestValidateCommandJSON(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	err := run([]string{"validate", "-json", "-schema", "testdata/validate/config.schema.yaml",
+		"testdata/validate/valid.json", "testdata/validate/invalid.yaml"}, stdout, ioutil.Discard)
+	require.Equal(t, errInvalid, err)
+
+	var results []fileResult
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+	require.Len(t, results, 2)
+	require.Equal(t, fileResult{File: "testdata/validate/valid.json", Valid: true}, results[0])
+	require.False(t, results[1].Valid)
+	require.Equal(t, fileError{
+		Line:         2,
+		Column:       9,
+		InstancePath: "/port",
+		SchemaPath:   "/properties/port/$ref/maximum",
+		Keyword:      "maximum",
+		Message:      "70000 is greater than 65535",
+	}, results[1].Errors[1])
+}
+
+func TestParseJSONPositions(t *testing.T) {
+	d, err := parseJSON([]byte("{\n  \"a\": [1,\n    {\"b~/\": true}],\n  \"c\" : null\n}"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]position{
+		"":           {1, 1},
+		"/a":         {2, 8},
+		"/a/0":       {2, 9},
+		"/a/1":       {3, 5},
+		"/a/1/b~0~1": {3, 13},
+		"/c":         {4, 9},
+	}, d.positions)
+	require.Equal(t, position{3, 5}, d.position("/a/1/missing"))
+}
+
+func TestParseYAMLAliases(t *testing.T) {
+	documents, err := parseYAML([]byte("a: &a [1, 2]\nb: [*a, *a]\n"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"a": []interface{}{json.Number("1"), json.Number("2")},
+		"b": []interface{}{
+			[]interface{}{json.Number("1"), json.Number("2")},
+			[]interface{}{json.Number("1"), json.Number("2")},
+		},
+	}, documents[0].value)
+
+	laughs := "a: &a [lol, lol, lol, lol, lol, lol, lol, lol, lol]\n"
+	for _, name := range []string{"b", "c", "d", "e", "f", "g", "h", "i"} {
+		prev := string(rune(name[0] - 1))
+		laughs += name + ": &" + name + " [" + strings.Repeat("*"+prev+", ", 8) + "*" + prev + "]\n"
+	}
+	_, err = parseYAML([]byte(laughs))
+	require.EqualError(t, err, "aliases expand to more than 100000 values")
+}
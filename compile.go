@@ -0,0 +1,718 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Validator validates instances against a compiled schema, as
+// Schema.Validate does, without walking the schema again for each instance.
+// Patterns are compiled, references resolved and lookup tables built once, by
+// Compile. A Validator is safe for concurrent use.
+type Validator struct {
+	root *compiledSchema
+}
+
+// Compile compiles s into a Validator, with the default options of Resolver.
+// References to other documents cannot be resolved; use Resolver.Compile for
+// schemas that have them.
+func Compile(s *Schema) (*Validator, error) {
+	r := NewResolver(nil)
+	if err := r.AddSchema("", s); err != nil {
+		return nil, err
+	}
+	return r.Compile(s.Type)
+}
+
+// Compile compiles t, a schema in one of the documents of the resolver, into
+// a Validator, with the options of the resolver. Unlike Validate, which
+// reports them as failures of each instance, it returns an error if a
+// reference cannot be resolved, a pattern does not compile, or a format is
+// unknown with StrictFormats.
+func (r *Resolver) Compile(t *Type) (*Validator, error) {
+	sc := &schemaCompiler{resolver: r, compiled: map[*Type]*compiledSchema{}}
+	root, err := sc.compile(t, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{root: root}, nil
+}
+
+// Validate validates instance, a value decoded from JSON, as Schema.Validate
+// does.
+func (v *Validator) Validate(instance interface{}) error {
+	run := &validation{}
+	run.validate(v.root, instance)
+	if len(run.errors) > 0 {
+		return run.errors
+	}
+	return nil
+}
+
+// ValidateJSON decodes the JSON document data and validates it.
+func (v *Validator) ValidateJSON(data []byte) error {
+	var instance interface{}
+	if err := decodeJSON(data, &instance); err != nil {
+		return err
+	}
+	return v.Validate(instance)
+}
+
+// compiledSchema is a schema prepared for validation, with its keywords
+// parsed and its subschemas compiled.
+type compiledSchema struct {
+	boolean bool // the schema is true or false
+	allowed bool // the value of a boolean schema
+	ref     *edge
+
+	types                []string
+	enum                 map[string]bool // canonical JSON of the allowed values
+	enumMessage          string
+	hasConst             bool
+	constValue           string // canonical JSON of the constant
+	multipleOf           *big.Rat
+	divisor              json.Number
+	maximum              bound
+	minimum              bound
+	maxLength            int // -1 if absent
+	minLength            int
+	pattern              *regexp.Regexp
+	format               func(string) bool
+	formatName           string
+	maxItems             int // -1 if absent
+	minItems             int
+	uniqueItems          bool
+	items                *edge
+	tupleItems           []*edge // nil unless items is an array
+	additional           *edge   // additionalItems
+	contains             *compiledSchema
+	maxProps             int // -1 if absent
+	minProps             int
+	required             []string
+	properties           map[string]*edge
+	patternProps         []patternEdge
+	additionalProperties *edge
+	closed               bool // additionalProperties is false
+	propertyNames        *compiledSchema
+	dependencies         []dependency
+	dependentRequired    []dependentRequired
+	allOf                []*edge
+	anyOf                []*compiledSchema
+	oneOf                []*compiledSchema
+	not                  *compiledSchema
+	ifSchema             *compiledSchema
+	then                 *edge
+	elseSchema           *edge
+	keywords             []customKeyword
+}
+
+// edge is a subschema with its path relative to its parent.
+type edge struct {
+	schema *compiledSchema
+	path   string
+}
+
+type patternEdge struct {
+	edge
+	re *regexp.Regexp
+}
+
+type dependency struct {
+	edge
+	name string
+}
+
+type dependentRequired struct {
+	name       string
+	properties []string
+}
+
+// bound is a parsed maximum or minimum.
+type bound struct {
+	ok        bool
+	value     float64
+	exclusive bool
+	keyword   string // the keyword that failed when an exclusive bound is reached
+}
+
+// schemaCompiler compiles the schemas of a resolver, once each.
+type schemaCompiler struct {
+	resolver *Resolver
+	compiled map[*Type]*compiledSchema
+}
+
+// compile compiles t, found at path in the schema being compiled.
+func (c *schemaCompiler) compile(t *Type, path string) (*compiledSchema, error) {
+	if t == nil {
+		return nil, nil
+	}
+	if cs, ok := c.compiled[t]; ok {
+		return cs, nil
+	}
+	cs := &compiledSchema{}
+	c.compiled[t] = cs
+	if allowed, ok := t.Boolean(); ok {
+		cs.boolean, cs.allowed = true, allowed
+		return cs, nil
+	}
+	if t.Ref != "" {
+		target, _, err := c.resolver.Resolve(c.resolver.Base(t), t.Ref)
+		if err != nil {
+			return nil, err
+		}
+		ref, err := c.edge(target, path, "/$ref")
+		cs.ref = ref
+		return cs, err
+	}
+
+	cs.types = t.typeList()
+	if len(t.Enum) > 0 {
+		cs.enum = make(map[string]bool, len(t.Enum))
+		values := make([]string, len(t.Enum))
+		for i, value := range t.Enum {
+			values[i] = canonicalJSON(value)
+			cs.enum[values[i]] = true
+		}
+		cs.enumMessage = "value must be one of " + strings.Join(values, ", ")
+	}
+	if t.HasConst() {
+		cs.hasConst, cs.constValue = true, canonicalJSON(t.Const)
+	}
+	if t.MultipleOf != "" {
+		cs.multipleOf, cs.divisor = parseDivisor(t.MultipleOf), t.MultipleOf
+	}
+	cs.maximum.value, cs.maximum.exclusive, cs.maximum.ok = t.maximum()
+	cs.maximum.keyword = boundKeyword("maximum", t.ExclusiveMaximum)
+	cs.minimum.value, cs.minimum.exclusive, cs.minimum.ok = t.minimum()
+	cs.minimum.keyword = boundKeyword("minimum", t.ExclusiveMinimum)
+	cs.maxLength, cs.minLength = maxCount(t.MaxLength), t.MinLength
+	if t.Pattern != "" {
+		re, err := compilePattern(t.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: %s/pattern: invalid pattern %q: %s", path, t.Pattern, err)
+		}
+		cs.pattern = re
+	}
+	if t.Format != "" {
+		check, ok := lookupFormat(t.Format)
+		if !ok && c.resolver.StrictFormats {
+			return nil, fmt.Errorf("jsonschema: %s/format: unknown format %q", path, t.Format)
+		}
+		cs.format, cs.formatName = check, t.Format
+	}
+
+	cs.maxItems, cs.minItems, cs.uniqueItems = maxCount(t.MaxItems), t.MinItems, t.UniqueItems
+	var err error
+	if t.TupleItems != nil {
+		cs.tupleItems = make([]*edge, len(t.TupleItems))
+		for i, sub := range t.TupleItems {
+			if cs.tupleItems[i], err = c.edge(sub, path, "/items/"+strconv.Itoa(i)); err != nil {
+				return nil, err
+			}
+		}
+		if cs.additional, err = c.edge(t.AdditionalItems, path, "/additionalItems"); err != nil {
+			return nil, err
+		}
+	} else if cs.items, err = c.edge(t.Items, path, "/items"); err != nil {
+		return nil, err
+	}
+	if cs.contains, err = c.compile(t.Contains, path+"/contains"); err != nil {
+		return nil, err
+	}
+
+	cs.maxProps, cs.minProps, cs.required = maxCount(t.MaxProperties), t.MinProperties, t.Required
+	if keys := t.Properties.Keys(); len(keys) > 0 {
+		cs.properties = make(map[string]*edge, len(keys))
+		for _, name := range keys {
+			sub, _ := t.Properties.Get(name)
+			if cs.properties[name], err = c.edge(sub, path, "/properties/"+escapePointerToken(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, pattern := range sortedKeys(t.PatternProperties) {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: %s/patternProperties: invalid pattern %q: %s", path, pattern, err)
+		}
+		sub, err := c.edge(t.PatternProperties[pattern], path, "/patternProperties/"+escapePointerToken(pattern))
+		if err != nil {
+			return nil, err
+		}
+		cs.patternProps = append(cs.patternProps, patternEdge{*sub, re})
+	}
+	if t.closed() {
+		cs.closed = true
+	} else if cs.additionalProperties, err = c.edge(t.AdditionalProperties, path, "/additionalProperties"); err != nil {
+		return nil, err
+	}
+	if cs.propertyNames, err = c.compile(t.PropertyNames, path+"/propertyNames"); err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(t.Dependencies) {
+		sub, err := c.edge(t.Dependencies[name], path, "/dependencies/"+escapePointerToken(name))
+		if err != nil {
+			return nil, err
+		}
+		cs.dependencies = append(cs.dependencies, dependency{*sub, name})
+	}
+	for _, name := range sortedStringKeys(t.DependentRequired) {
+		cs.dependentRequired = append(cs.dependentRequired, dependentRequired{name, t.DependentRequired[name]})
+	}
+
+	for i, sub := range t.AllOf {
+		e, err := c.edge(sub, path, "/allOf/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		cs.allOf = append(cs.allOf, e)
+	}
+	if cs.anyOf, err = c.compileAll(t.AnyOf, path+"/anyOf/"); err != nil {
+		return nil, err
+	}
+	if cs.oneOf, err = c.compileAll(t.OneOf, path+"/oneOf/"); err != nil {
+		return nil, err
+	}
+	if cs.not, err = c.compile(t.Not, path+"/not"); err != nil {
+		return nil, err
+	}
+	if t.If != nil {
+		if cs.ifSchema, err = c.compile(t.If, path+"/if"); err != nil {
+			return nil, err
+		}
+		if cs.then, err = c.edge(t.Then, path, "/then"); err != nil {
+			return nil, err
+		}
+		if cs.elseSchema, err = c.edge(t.Else, path, "/else"); err != nil {
+			return nil, err
+		}
+	}
+	cs.keywords = t.registeredKeywords()
+	return cs, nil
+}
+
+// edge compiles the subschema t, found at path+relative.
+func (c *schemaCompiler) edge(t *Type, path, relative string) (*edge, error) {
+	if t == nil {
+		return nil, nil
+	}
+	cs, err := c.compile(t, path+relative)
+	if err != nil {
+		return nil, err
+	}
+	return &edge{cs, relative}, nil
+}
+
+func (c *schemaCompiler) compileAll(types []*Type, path string) ([]*compiledSchema, error) {
+	var compiled []*compiledSchema
+	for i, t := range types {
+		cs, err := c.compile(t, path+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, cs)
+	}
+	return compiled, nil
+}
+
+// validation is a run of a Validator over one instance. Paths are kept as
+// stacks of tokens, and only joined when a failure is recorded.
+type validation struct {
+	instancePath []pathToken
+	schemaPath   []string
+	errors       ValidationErrors
+	quiet        int  // failures are not recorded within valid
+	failed       bool // a failure occurred within valid
+}
+
+// pathToken is a token of an instance path: an index, or a property name if
+// the index is -1.
+type pathToken struct {
+	name  string
+	index int
+}
+
+func (v *validation) fail(keyword, format string, args ...interface{}) {
+	if v.quiet > 0 {
+		v.failed = true
+		return
+	}
+	v.errors = append(v.errors, &ValidationError{
+		InstancePath: v.instancePointer(),
+		SchemaPath:   strings.Join(v.schemaPath, "") + "/" + keyword,
+		Keyword:      keyword,
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validation) instancePointer() string {
+	b := &strings.Builder{}
+	for _, token := range v.instancePath {
+		b.WriteByte('/')
+		if token.index < 0 {
+			b.WriteString(escapePointerToken(token.name))
+		} else {
+			b.WriteString(strconv.Itoa(token.index))
+		}
+	}
+	return b.String()
+}
+
+// valid reports whether instance is valid against cs, without recording any
+// failures.
+func (v *validation) valid(cs *compiledSchema, instance interface{}) bool {
+	failed := v.failed
+	v.failed = false
+	v.quiet++
+	v.validate(cs, instance)
+	v.quiet--
+	ok := !v.failed
+	v.failed = failed
+	return ok
+}
+
+// sub validates instance against the subschema e.
+func (v *validation) sub(e *edge, instance interface{}) {
+	v.schemaPath = append(v.schemaPath, e.path)
+	v.validate(e.schema, instance)
+	v.schemaPath = v.schemaPath[:len(v.schemaPath)-1]
+}
+
+func (v *validation) validate(cs *compiledSchema, instance interface{}) {
+	if v.quiet > 0 && v.failed {
+		return
+	}
+	if cs.boolean {
+		if !cs.allowed {
+			if v.quiet > 0 {
+				v.failed = true
+				return
+			}
+			v.errors = append(v.errors, &ValidationError{
+				InstancePath: v.instancePointer(),
+				SchemaPath:   strings.Join(v.schemaPath, ""),
+				Keyword:      "false",
+				Message:      "no value is allowed",
+			})
+		}
+		return
+	}
+	if cs.ref != nil {
+		v.sub(cs.ref, instance)
+		return
+	}
+
+	if len(cs.types) > 0 {
+		actual := jsonType(instance)
+		if !coversType(cs.types, actual) {
+			v.fail("type", "expected %s, but got %s", strings.Join(cs.types, " or "), actual)
+		}
+	}
+	if cs.enum != nil && !cs.enum[canonicalJSON(decoded(instance))] {
+		v.fail("enum", "%s", cs.enumMessage)
+	}
+	if cs.hasConst && canonicalJSON(decoded(instance)) != cs.constValue {
+		v.fail("const", "value must be %s", cs.constValue)
+	}
+
+	switch value := instance.(type) {
+	case string:
+		v.validateString(cs, value)
+	case []interface{}:
+		v.validateArray(cs, jsonArray(value))
+	case map[string]interface{}:
+		v.validateObject(cs, instance, jsonObject(value))
+	case array:
+		v.validateArray(cs, value)
+	case object:
+		v.validateObject(cs, instance, value)
+	default:
+		if n, ok := toFloat(instance); ok {
+			v.validateNumber(cs, instance, n)
+		}
+	}
+	v.validateCombinators(cs, instance)
+	if len(cs.keywords) > 0 {
+		instance = decoded(instance)
+	}
+	for _, keyword := range cs.keywords {
+		if err := keyword.validate(keyword.value, instance); err != nil {
+			v.fail(keyword.name, "%s", err)
+		}
+	}
+}
+
+func (v *validation) validateNumber(cs *compiledSchema, instance interface{}, n float64) {
+	if cs.multipleOf != nil && !isMultipleOfRat(instance, cs.multipleOf) {
+		v.fail("multipleOf", "%s is not a multiple of %s", formatNumber(n), cs.divisor)
+	}
+	if max := cs.maximum; max.ok {
+		if max.exclusive && n >= max.value {
+			v.fail(max.keyword, "%s is not less than %s", formatNumber(n), formatNumber(max.value))
+		} else if n > max.value {
+			v.fail("maximum", "%s is greater than %s", formatNumber(n), formatNumber(max.value))
+		}
+	}
+	if min := cs.minimum; min.ok {
+		if min.exclusive && n <= min.value {
+			v.fail(min.keyword, "%s is not greater than %s", formatNumber(n), formatNumber(min.value))
+		} else if n < min.value {
+			v.fail("minimum", "%s is less than %s", formatNumber(n), formatNumber(min.value))
+		}
+	}
+}
+
+func (v *validation) validateString(cs *compiledSchema, s string) {
+	if cs.maxLength >= 0 || cs.minLength > 0 {
+		length := utf8.RuneCountInString(s)
+		if cs.maxLength >= 0 && length > cs.maxLength {
+			v.fail("maxLength", "string is longer than %d characters", cs.maxLength)
+		}
+		if cs.minLength > 0 && length < cs.minLength {
+			v.fail("minLength", "string is shorter than %d characters", cs.minLength)
+		}
+	}
+	if cs.pattern != nil && !cs.pattern.MatchString(s) {
+		v.fail("pattern", "string does not match pattern %q", cs.pattern.String())
+	}
+	if cs.format != nil && !cs.format(s) {
+		v.fail("format", "%q is not a valid %s", s, cs.formatName)
+	}
+}
+
+func (v *validation) validateArray(cs *compiledSchema, items array) {
+	n := items.len()
+	if cs.maxItems >= 0 && n > cs.maxItems {
+		v.fail("maxItems", "array has more than %d items", cs.maxItems)
+	}
+	if cs.minItems > 0 && n < cs.minItems {
+		v.fail("minItems", "array has fewer than %d items", cs.minItems)
+	}
+	if cs.uniqueItems {
+		seen := make(map[string]int, n)
+		for i := 0; i < n; i++ {
+			key := canonicalJSON(decoded(items.index(i)))
+			if j, ok := seen[key]; ok {
+				v.fail("uniqueItems", "items %d and %d are equal", j, i)
+				break
+			}
+			seen[key] = i
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		e := cs.items
+		if cs.tupleItems != nil {
+			e = cs.additional
+			if i < len(cs.tupleItems) {
+				e = cs.tupleItems[i]
+			}
+		}
+		if e == nil {
+			continue
+		}
+		v.instancePath = append(v.instancePath, pathToken{index: i})
+		v.sub(e, items.index(i))
+		v.instancePath = v.instancePath[:len(v.instancePath)-1]
+	}
+
+	if cs.contains != nil {
+		found := false
+		for i := 0; i < n; i++ {
+			if v.valid(cs.contains, items.index(i)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail("contains", "no item matches the contains schema")
+		}
+	}
+}
+
+// validateObject validates object, which is read from instance.
+func (v *validation) validateObject(cs *compiledSchema, instance interface{}, object object) {
+	if cs.maxProps >= 0 && object.len() > cs.maxProps {
+		v.fail("maxProperties", "object has more than %d properties", cs.maxProps)
+	}
+	if cs.minProps > 0 && object.len() < cs.minProps {
+		v.fail("minProperties", "object has fewer than %d properties", cs.minProps)
+	}
+	for _, name := range cs.required {
+		if _, ok := object.property(name); !ok {
+			v.fail("required", "missing required property %q", name)
+		}
+	}
+
+	if cs.properties != nil || cs.patternProps != nil || cs.additionalProperties != nil || cs.closed || cs.propertyNames != nil {
+		for _, name := range object.names() {
+			value, _ := object.property(name)
+			v.validateProperty(cs, name, value)
+		}
+	}
+
+	for _, d := range cs.dependencies {
+		if _, ok := object.property(d.name); ok {
+			v.sub(&d.edge, instance)
+		}
+	}
+	for _, d := range cs.dependentRequired {
+		if _, ok := object.property(d.name); !ok {
+			continue
+		}
+		for _, dependency := range d.properties {
+			if _, ok := object.property(dependency); !ok {
+				v.fail("dependencies", "property %q requires property %q", d.name, dependency)
+			}
+		}
+	}
+}
+
+// validateProperty validates the property name of an object, whose value is
+// value, recording failures in the same order as Validate.
+func (v *validation) validateProperty(cs *compiledSchema, name string, value interface{}) {
+	v.instancePath = append(v.instancePath, pathToken{name: name, index: -1})
+	// A failure of propertyNames is recorded before those of the value.
+	invalidName := cs.propertyNames != nil && !v.valid(cs.propertyNames, name)
+	matched := false
+	if e, ok := cs.properties[name]; ok {
+		matched, invalidName = true, v.propertyName(invalidName, name)
+		v.sub(e, value)
+	}
+	for i := range cs.patternProps {
+		if cs.patternProps[i].re.MatchString(name) {
+			matched, invalidName = true, v.propertyName(invalidName, name)
+			v.sub(&cs.patternProps[i].edge, value)
+		}
+	}
+	if !matched {
+		if cs.closed {
+			v.fail("additionalProperties", "property %q is not allowed", name)
+		} else if cs.additionalProperties != nil {
+			invalidName = v.propertyName(invalidName, name)
+			v.sub(cs.additionalProperties, value)
+		}
+	}
+	v.propertyName(invalidName, name)
+	v.instancePath = v.instancePath[:len(v.instancePath)-1]
+}
+
+// propertyName records the failure of propertyNames if invalid, and returns
+// false once it is recorded.
+func (v *validation) propertyName(invalid bool, name string) bool {
+	if invalid {
+		v.fail("propertyNames", "property name %q is not valid", name)
+	}
+	return false
+}
+
+func (v *validation) validateCombinators(cs *compiledSchema, instance interface{}) {
+	for _, e := range cs.allOf {
+		v.sub(e, instance)
+	}
+	if len(cs.anyOf) > 0 {
+		matched := false
+		for _, sub := range cs.anyOf {
+			if v.valid(sub, instance) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail("anyOf", "value does not match any of the anyOf schemas")
+		}
+	}
+	if len(cs.oneOf) > 0 {
+		var matches []string
+		for i, sub := range cs.oneOf {
+			if v.valid(sub, instance) {
+				matches = append(matches, strconv.Itoa(i))
+			}
+		}
+		switch len(matches) {
+		case 0:
+			v.fail("oneOf", "value does not match any of the oneOf schemas")
+		case 1:
+		default:
+			v.fail("oneOf", "value matches more than one of the oneOf schemas: %s", strings.Join(matches, ", "))
+		}
+	}
+	if cs.not != nil && v.valid(cs.not, instance) {
+		v.fail("not", "value must not match the not schema")
+	}
+	if cs.ifSchema != nil {
+		if v.valid(cs.ifSchema, instance) {
+			if cs.then != nil {
+				v.sub(cs.then, instance)
+			}
+		} else if cs.elseSchema != nil {
+			v.sub(cs.elseSchema, instance)
+		}
+	}
+}
+
+// An array is an array instance that the validation reads in place, either a
+// decoded []interface{} or a Go value validated by ValidateValue.
+type array interface {
+	len() int
+	index(i int) interface{}
+}
+
+// An object is an object instance that the validation reads in place, either a
+// decoded map[string]interface{} or a Go value validated by ValidateValue.
+type object interface {
+	len() int
+	names() []string // sorted
+	property(name string) (interface{}, bool)
+}
+
+type jsonArray []interface{}
+
+func (a jsonArray) len() int                { return len(a) }
+func (a jsonArray) index(i int) interface{} { return a[i] }
+
+type jsonObject map[string]interface{}
+
+func (o jsonObject) len() int { return len(o) }
+
+func (o jsonObject) names() []string {
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (o jsonObject) property(name string) (interface{}, bool) {
+	value, ok := o[name]
+	return value, ok
+}
+
+// decoded returns instance as decoded from JSON, reading the arrays and
+// objects within it, for the keywords that compare whole values.
+func decoded(instance interface{}) interface{} {
+	switch value := instance.(type) {
+	case array:
+		items := make([]interface{}, value.len())
+		for i := range items {
+			items[i] = decoded(value.index(i))
+		}
+		return items
+	case object:
+		names := value.names()
+		properties := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			property, _ := value.property(name)
+			properties[name] = decoded(property)
+		}
+		return properties
+	}
+	return instance
+}
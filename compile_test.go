@@ -0,0 +1,137 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	for _, test := range validateTests {
+		t.Run(test.schema+" "+test.instance, func(t *testing.T) {
+			s := mustSchema(t, test.schema)
+			v, err := Compile(s)
+			if strings.Contains(test.schema, "other.json") {
+				require.EqualError(t, err, `jsonschema: no schema for "other.json"`)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, s.ValidateJSON([]byte(test.instance)), v.ValidateJSON([]byte(test.instance)))
+		})
+	}
+}
+
+func TestCompileSchemaPaths(t *testing.T) {
+	s := mustSchema(t, benchmarkSchema)
+	v, err := Compile(s)
+	require.NoError(t, err)
+	instance := `{"id": "x", "lines": [{"sku": "a", "quantity": 0, "extra": true}, {"sku": "B"}], "tags": ["a", "a"],
+		"status": {"open": true}, "x-a": 1, "point": [1, "2", 3], "~/": null, "x-a-long-name": 2}`
+	expected := s.ValidateJSON([]byte(instance))
+	require.Len(t, expected, 13)
+	require.Equal(t, expected, v.ValidateJSON([]byte(instance)))
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := Compile(mustSchema(t, `{"properties": {"a": {"pattern": "("}}}`))
+	require.EqualError(t, err, "jsonschema: /properties/a/pattern: invalid pattern \"(\": error parsing regexp: missing closing ): `(`")
+	_, err = Compile(mustSchema(t, `{"patternProperties": {"(": {}}}`))
+	require.EqualError(t, err, "jsonschema: /patternProperties: invalid pattern \"(\": error parsing regexp: missing closing ): `(`")
+}
+
+func TestCompileRecursive(t *testing.T) {
+	s := mustSchema(t, `{
+		"definitions": {"node": {"properties": {"value": {"type": "integer"}, "next": {"$ref": "#/definitions/node"}}}},
+		"$ref": "#/definitions/node"
+	}`)
+	v, err := Compile(s)
+	require.NoError(t, err)
+	require.NoError(t, v.ValidateJSON([]byte(`{"value": 1, "next": {"value": 2}}`)))
+	require.EqualError(t, v.ValidateJSON([]byte(`{"value": 1, "next": {"next": {"value": "3"}}}`)), "/next/next/value: expected integer, but got string")
+}
+
+func TestValidatorConcurrent(t *testing.T) {
+	v, err := Compile(mustSchema(t, benchmarkSchema))
+	require.NoError(t, err)
+	var instance interface{}
+	require.NoError(t, decodeJSON([]byte(benchmarkInstance), &instance))
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				require.NoError(t, v.Validate(instance))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+const benchmarkSchema = `{
+	"definitions": {
+		"line": {
+			"type": "object",
+			"required": ["sku", "quantity"],
+			"properties": {
+				"sku": {"type": "string", "pattern": "^[A-Z]+$"},
+				"quantity": {"type": "integer", "minimum": 1, "multipleOf": 1}
+			},
+			"additionalProperties": false
+		}
+	},
+	"type": "object",
+	"required": ["id"],
+	"properties": {
+		"id": {"type": "string", "minLength": 2, "format": "uuid"},
+		"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}, "maxItems": 100},
+		"tags": {"type": "array", "items": {"enum": ["a", "b", "c", "d"]}, "uniqueItems": true},
+		"status": {"oneOf": [{"const": "open"}, {"type": "object", "required": ["closed"]}]},
+		"point": {"items": [{"type": "number"}, {"type": "number"}], "additionalItems": false}
+	},
+	"patternProperties": {"^x-": {"type": "string"}},
+	"propertyNames": {"maxLength": 10},
+	"allOf": [{"maxProperties": 8}],
+	"dependencies": {"point": ["lines"]}
+}`
+
+const benchmarkInstance = `{
+	"id": "0b0a8a4e-7c0e-4c5e-9e6a-6f1a1c7e2b3d",
+	"lines": [
+		{"sku": "APPLE", "quantity": 3},
+		{"sku": "PEAR", "quantity": 1},
+		{"sku": "PLUM", "quantity": 12},
+		{"sku": "FIG", "quantity": 2}
+	],
+	"tags": ["a", "c", "d"],
+	"status": "open",
+	"point": [1.5, 2],
+	"x-source": "web"
+}`
+
+func BenchmarkValidate(b *testing.B) {
+	s := &Schema{}
+	require.NoError(b, json.Unmarshal([]byte(benchmarkSchema), s))
+	var instance interface{}
+	require.NoError(b, decodeJSON([]byte(benchmarkInstance), &instance))
+	b.Run("Interpreted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := s.Validate(instance); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Compiled", func(b *testing.B) {
+		v, err := Compile(s)
+		require.NoError(b, err)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := v.Validate(instance); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
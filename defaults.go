@@ -0,0 +1,159 @@
+package jsonschema
+
+import "encoding/json"
+
+// ApplyDefaults fills in the properties missing from instance that have a
+// default in s, and returns the result. The instance is a value decoded from
+// JSON, whose objects and arrays are modified in place; it is only replaced
+// if it is nil and the root schema has a default.
+//
+// Defaults are found through properties, items, allOf and references. The
+// default of a property is copied into the instance before its own missing
+// properties are filled in, so nested defaults apply to it too, save for the
+// default of the same property within its own default, which would otherwise
+// be filled in forever through a recursive reference. References to
+// other documents cannot be resolved; use Resolver.ApplyDefaults for schemas
+// that have them.
+func (s *Schema) ApplyDefaults(instance interface{}) (interface{}, error) {
+	r := NewResolver(nil)
+	if err := r.AddSchema("", s); err != nil {
+		return nil, err
+	}
+	return r.ApplyDefaults(s.Type, instance)
+}
+
+// ApplyDefaultsJSON decodes the JSON document data, fills in its missing
+// properties as ApplyDefaults does, and returns it encoded.
+func (s *Schema) ApplyDefaultsJSON(data []byte) ([]byte, error) {
+	var instance interface{}
+	if err := decodeJSON(data, &instance); err != nil {
+		return nil, err
+	}
+	instance, err := s.ApplyDefaults(instance)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(instance)
+}
+
+// ApplyDefaults fills in the properties missing from instance that have a
+// default in t, a schema in one of the documents of the resolver, as
+// Schema.ApplyDefaults does.
+func (r *Resolver) ApplyDefaults(t *Type, instance interface{}) (interface{}, error) {
+	if t == nil {
+		return instance, nil
+	}
+	inserted := map[*Type]bool{}
+	if instance == nil {
+		if def, ok, err := r.defaultValue(t); err != nil {
+			return nil, err
+		} else if ok {
+			instance = copyJSON(def)
+			inserted[t] = true
+		}
+	}
+	return instance, r.applyDefaults(t, instance, map[*Type]bool{}, inserted)
+}
+
+// applyDefaults fills in the defaults of t in instance. seen holds the
+// schemas already applied to the same instance, so that cyclic references
+// end. inserted holds the schemas whose defaults were filled in on the way
+// to instance, whose defaults are not filled in again within them.
+func (r *Resolver) applyDefaults(t *Type, instance interface{}, seen, inserted map[*Type]bool) error {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	if t.Ref != "" {
+		target, err := r.Deref(t)
+		if err != nil {
+			return err
+		}
+		return r.applyDefaults(target, instance, seen, inserted)
+	}
+	for _, sub := range t.AllOf {
+		if err := r.applyDefaults(sub, instance, seen, inserted); err != nil {
+			return err
+		}
+	}
+
+	switch value := instance.(type) {
+	case map[string]interface{}:
+		for _, name := range t.Properties.Keys() {
+			sub, _ := t.Properties.Get(name)
+			if _, ok := value[name]; !ok {
+				if inserted[sub] {
+					continue
+				}
+				def, ok, err := r.defaultValue(sub)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+				value[name] = copyJSON(def)
+				inserted[sub] = true
+				err = r.applyDefaults(sub, value[name], map[*Type]bool{}, inserted)
+				delete(inserted, sub)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if err := r.applyDefaults(sub, value[name], map[*Type]bool{}, inserted); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			sub := t.Items
+			if t.TupleItems != nil {
+				sub = t.AdditionalItems
+				if i < len(t.TupleItems) {
+					sub = t.TupleItems[i]
+				}
+			}
+			if sub == nil {
+				continue
+			}
+			if err := r.applyDefaults(sub, item, map[*Type]bool{}, inserted); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// defaultValue returns the default of t, or of the schema it refers to, and
+// whether there is one. A default may be null.
+func (r *Resolver) defaultValue(t *Type) (interface{}, bool, error) {
+	if !t.HasDefault() && t.Ref != "" {
+		target, err := r.Deref(t)
+		if err != nil {
+			return nil, false, err
+		}
+		t = target
+	}
+	return t.Default, t.HasDefault(), nil
+}
+
+// copyJSON returns a deep copy of a JSON value, so that a default filled in
+// more than once is not shared.
+func copyJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			c[key] = copyJSON(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = copyJSON(item)
+		}
+		return c
+	}
+	return value
+}
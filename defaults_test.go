@@ -0,0 +1,136 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ServerConfig struct {
+	Host    string         `json:"host" jsonschema:"default=localhost"`
+	Port    int            `json:"port" jsonschema:"default=8080"`
+	TLS     *TLSConfig     `json:"tls,omitempty"`
+	Proxies []ProxyConfig  `json:"proxies,omitempty"`
+	Labels  map[string]int `json:"labels,omitempty"`
+}
+
+type TLSConfig struct {
+	Enabled  bool   `json:"enabled"`
+	CertFile string `json:"cert_file" jsonschema:"default=server.crt"`
+}
+
+type ProxyConfig struct {
+	URL     string `json:"url"`
+	Timeout int    `json:"timeout" jsonschema:"default=30"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	s := Reflect(&ServerConfig{})
+	actual, err := s.ApplyDefaultsJSON([]byte(`{"port": 9090, "tls": {"enabled": true}, "proxies": [{"url": "a"}, {"url": "b", "timeout": 5}]}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"host": "localhost",
+		"port": 9090,
+		"tls": {"enabled": true, "cert_file": "server.crt"},
+		"proxies": [{"url": "a", "timeout": 30}, {"url": "b", "timeout": 5}]
+	}`, string(actual))
+}
+
+func TestApplyDefaultsNested(t *testing.T) {
+	s := mustSchema(t, `{
+		"definitions": {
+			"limits": {"properties": {"max": {"default": 10}, "min": {"default": 0}}},
+			"node": {"properties": {"name": {"default": "node"}, "child": {"$ref": "#/definitions/node"}}}
+		},
+		"properties": {
+			"limits": {"$ref": "#/definitions/limits", "default": {"max": 5}},
+			"tags": {"default": ["a"]},
+			"tuple": {"items": [{"properties": {"x": {"default": 1}}}], "additionalItems": {"properties": {"y": {"default": 2}}}},
+			"tree": {"$ref": "#/definitions/node"}
+		},
+		"allOf": [{"properties": {"version": {"default": 1}}}]
+	}`)
+	instance := map[string]interface{}{
+		"tuple": []interface{}{map[string]interface{}{}, map[string]interface{}{}},
+		"tree":  map[string]interface{}{"child": map[string]interface{}{}},
+	}
+	actual, err := s.ApplyDefaults(instance)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"limits":  map[string]interface{}{"max": 5.0, "min": 0.0},
+		"tags":    []interface{}{"a"},
+		"tuple":   []interface{}{map[string]interface{}{"x": 1.0}, map[string]interface{}{"y": 2.0}},
+		"tree":    map[string]interface{}{"name": "node", "child": map[string]interface{}{"name": "node"}},
+		"version": 1.0,
+	}, normalizeNumbers(actual))
+
+	// Defaults are copied, not shared between documents.
+	actual.(map[string]interface{})["tags"].([]interface{})[0] = "b"
+	second, err := s.ApplyDefaults(map[string]interface{}{})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"a"}, second.(map[string]interface{})["tags"])
+}
+
+func TestApplyDefaultsRoot(t *testing.T) {
+	s := mustSchema(t, `{"default": {}, "properties": {"a": {"default": true}}}`)
+	actual, err := s.ApplyDefaults(nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"a": true}, actual)
+}
+
+// normalizeNumbers converts the json.Number values in v to float64.
+func normalizeNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeNumbers(item)
+		}
+	default:
+		if f, ok := toFloat(v); ok {
+			return f
+		}
+	}
+	return v
+}
+
+func TestApplyDefaultsRecursive(t *testing.T) {
+	s := mustSchema(t, `{
+		"definitions": {"N": {"type": "object", "properties": {"next": {"$ref": "#/definitions/N", "default": {}}}}},
+		"$ref": "#/definitions/N"
+	}`)
+	actual, err := s.ApplyDefaultsJSON([]byte(`{}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"next": {}}`, string(actual))
+	actual, err = s.ApplyDefaultsJSON([]byte(`{"next": {"next": {}}}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"next": {"next": {"next": {}}}}`, string(actual))
+
+	s = mustSchema(t, `{
+		"definitions": {
+			"A": {"properties": {"b": {"$ref": "#/definitions/B"}, "name": {"default": "a"}}, "default": {}},
+			"B": {"properties": {"a": {"$ref": "#/definitions/A"}}, "default": {}}
+		},
+		"$ref": "#/definitions/A"
+	}`)
+	actual, err = s.ApplyDefaultsJSON([]byte(`{}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name": "a", "b": {"a": {"name": "a"}}}`, string(actual))
+}
+
+func TestApplyDefaultsNull(t *testing.T) {
+	s := mustSchema(t, `{
+		"definitions": {"mode": {"type": ["string", "null"], "default": "fast"}},
+		"properties": {
+			"a": {"default": null},
+			"b": {"$ref": "#/definitions/mode", "default": null},
+			"c": {"$ref": "#/definitions/mode"}
+		}
+	}`)
+	actual, err := s.ApplyDefaultsJSON([]byte(`{}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a": null, "b": null, "c": "fast"}`, string(actual))
+}
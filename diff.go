@@ -0,0 +1,616 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Change is a difference between two versions of a schema.
+type Change struct {
+	// Path is the JSON Pointer of the changed keyword in the new schema or,
+	// if the keyword was removed, in the old one.
+	Path string
+	// Message describes the change.
+	Message string
+	// BreaksProducers is set when a document valid against the old schema
+	// may be invalid against the new one, so that producers of documents may
+	// have to change.
+	BreaksProducers bool
+	// BreaksConsumers is set when a document valid against the new schema
+	// may be invalid against the old one, so that consumers written against
+	// the old schema may receive documents they do not expect.
+	BreaksConsumers bool
+}
+
+// Breaking reports whether the change breaks producers or consumers.
+func (c Change) Breaking() bool {
+	return c.BreaksProducers || c.BreaksConsumers
+}
+
+func (c Change) String() string {
+	kind := "non-breaking"
+	switch {
+	case c.BreaksProducers && c.BreaksConsumers:
+		kind = "breaks producers and consumers"
+	case c.BreaksProducers:
+		kind = "breaks producers"
+	case c.BreaksConsumers:
+		kind = "breaks consumers"
+	}
+	return fmt.Sprintf("%s: %s (%s)", c.Path, c.Message, kind)
+}
+
+// Diff compares two versions of a schema, including their definitions, and
+// returns the changes between them in document order.
+//
+// A change that makes the schema stricter, such as a new required property, a
+// narrowed type, a removed enum value, a lower maxLength or additionalProperties
+// changed to false, breaks producers. A change that makes it more lenient
+// breaks consumers. Changes to annotations such as descriptions are reported
+// as non-breaking. References to definitions of the same name are not
+// followed, as the definitions are compared in their own right; other
+// references are compared by their targets.
+func Diff(old, new *Schema) []Change {
+	d := &differ{
+		oldResolver: NewResolver(nil),
+		newResolver: NewResolver(nil),
+		seen:        map[[2]*Type]bool{},
+	}
+	_ = d.oldResolver.AddSchema("", old)
+	_ = d.newResolver.AddSchema("", new)
+	if old.Type != nil && new.Type != nil {
+		d.compare("", old.Type, new.Type)
+	}
+	d.definitions("", old.Definitions, new.Definitions)
+	return d.changes
+}
+
+type differ struct {
+	oldResolver *Resolver
+	newResolver *Resolver
+	seen        map[[2]*Type]bool
+	changes     []Change
+}
+
+func (d *differ) add(path string, producers, consumers bool, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Path:            path,
+		Message:         fmt.Sprintf(format, args...),
+		BreaksProducers: producers,
+		BreaksConsumers: consumers,
+	})
+}
+
+// narrowed records a change that makes the schema stricter.
+func (d *differ) narrowed(path, format string, args ...interface{}) {
+	d.add(path, true, false, format, args...)
+}
+
+// widened records a change that makes the schema more lenient.
+func (d *differ) widened(path, format string, args ...interface{}) {
+	d.add(path, false, true, format, args...)
+}
+
+// changed records a change that makes the schema both stricter and more lenient.
+func (d *differ) changed(path, format string, args ...interface{}) {
+	d.add(path, true, true, format, args...)
+}
+
+// annotated records a change that does not affect validation.
+func (d *differ) annotated(path, format string, args ...interface{}) {
+	d.add(path, false, false, format, args...)
+}
+
+func (d *differ) definitions(path string, o, n Definitions) {
+	for _, name := range sortedKeys(n) {
+		p := path + "/definitions/" + escapePointerToken(name)
+		if od, ok := o[name]; ok {
+			d.compare(p, od, n[name])
+		} else {
+			d.annotated(p, "definition %q added", name)
+		}
+	}
+	for _, name := range sortedKeys(o) {
+		if _, ok := n[name]; !ok {
+			d.annotated(path+"/definitions/"+escapePointerToken(name), "definition %q removed", name)
+		}
+	}
+}
+
+func (d *differ) compare(path string, o, n *Type) {
+	pair := [2]*Type{o, n}
+	if d.seen[pair] {
+		return
+	}
+	d.seen[pair] = true
+
+	if o.Ref != "" || n.Ref != "" {
+		d.references(path, o, n)
+		return
+	}
+	if d.booleans(path, o, n) {
+		return
+	}
+
+	d.types(path, o, n)
+	d.enums(path, o, n)
+	d.numbers(path, o, n)
+	d.strings(path, o, n)
+	d.arrays(path, o, n)
+	d.objects(path, o, n)
+	d.combinators(path, o, n)
+	d.annotations(path, o, n)
+	d.definitions(path, o.Definitions, n.Definitions)
+}
+
+func (d *differ) references(path string, o, n *Type) {
+	if o.Ref == n.Ref {
+		if _, ok := definitionRef(o.Ref); ok {
+			return
+		}
+	}
+	ot, oerr := d.oldResolver.Deref(o)
+	nt, nerr := d.newResolver.Deref(n)
+	if oerr != nil || nerr != nil {
+		if o.Ref != n.Ref {
+			d.changed(path+"/$ref", "$ref changed from %q to %q", o.Ref, n.Ref)
+		}
+		return
+	}
+	if o.Ref != n.Ref && o.Ref != "" && n.Ref != "" {
+		d.annotated(path+"/$ref", "$ref changed from %q to %q", o.Ref, n.Ref)
+	}
+	d.compare(path, ot, nt)
+}
+
+// booleans compares schemas of which at least one is a boolean schema,
+// reporting whether it did.
+func (d *differ) booleans(path string, o, n *Type) bool {
+	ob, oIsBool := o.Boolean()
+	nb, nIsBool := n.Boolean()
+	switch {
+	case !oIsBool && !nIsBool:
+		return false
+	case oIsBool && nIsBool && ob == nb:
+	case oIsBool && nIsBool && ob:
+		d.narrowed(path, "schema changed from true to false")
+	case oIsBool && nIsBool:
+		d.widened(path, "schema changed from false to true")
+	case oIsBool && ob:
+		d.narrowed(path, "schema restricted from true")
+	case nIsBool && nb:
+		d.widened(path, "schema relaxed to true")
+	case oIsBool:
+		d.widened(path, "schema relaxed from false")
+	default:
+		d.narrowed(path, "schema restricted to false")
+	}
+	return true
+}
+
+func (d *differ) types(path string, o, n *Type) {
+	ot, nt := o.typeList(), n.typeList()
+	switch {
+	case len(ot) == 0 && len(nt) == 0:
+	case len(ot) == 0:
+		d.narrowed(path+"/type", "type restricted to %s", strings.Join(nt, ", "))
+	case len(nt) == 0:
+		d.widened(path+"/type", "type restriction to %s removed", strings.Join(ot, ", "))
+	default:
+		for _, typ := range ot {
+			if !coversType(nt, typ) {
+				d.narrowed(path+"/type", "type %s no longer allowed", typ)
+			}
+		}
+		for _, typ := range nt {
+			if !coversType(ot, typ) {
+				d.widened(path+"/type", "type %s now allowed", typ)
+			}
+		}
+	}
+}
+
+func (d *differ) enums(path string, o, n *Type) {
+	switch {
+	case o.Enum == nil && n.Enum == nil:
+	case o.Enum == nil:
+		d.narrowed(path+"/enum", "enum added")
+	case n.Enum == nil:
+		d.widened(path+"/enum", "enum removed")
+	default:
+		for _, value := range o.Enum {
+			if !containsJSON(n.Enum, value) {
+				d.narrowed(path+"/enum", "enum value %s removed", canonicalJSON(value))
+			}
+		}
+		for _, value := range n.Enum {
+			if !containsJSON(o.Enum, value) {
+				d.widened(path+"/enum", "enum value %s added", canonicalJSON(value))
+			}
+		}
+	}
+
+	switch {
+	case !o.HasConst() && !n.HasConst():
+	case !o.HasConst():
+		d.narrowed(path+"/const", "const %s added", canonicalJSON(n.Const))
+	case !n.HasConst():
+		d.widened(path+"/const", "const %s removed", canonicalJSON(o.Const))
+	case !equalJSON(o.Const, n.Const):
+		d.changed(path+"/const", "const changed from %s to %s", canonicalJSON(o.Const), canonicalJSON(n.Const))
+	}
+}
+
+func (d *differ) numbers(path string, o, n *Type) {
+	ov, oex, ook := o.maximum()
+	nv, nex, nok := n.maximum()
+	d.bound(path+"/maximum", "maximum", ov, oex, ook, nv, nex, nok, func(a, b float64) bool { return a < b })
+	ov, oex, ook = o.minimum()
+	nv, nex, nok = n.minimum()
+	d.bound(path+"/minimum", "minimum", ov, oex, ook, nv, nex, nok, func(a, b float64) bool { return a > b })
+
+	om, oerr := o.MultipleOf.Float64()
+	nm, nerr := n.MultipleOf.Float64()
+	p := path + "/multipleOf"
+	switch {
+	case oerr != nil && nerr != nil, oerr == nil && nerr == nil && om == nm:
+	case oerr != nil:
+		d.narrowed(p, "multipleOf %s added", n.MultipleOf)
+	case nerr != nil:
+		d.widened(p, "multipleOf %s removed", o.MultipleOf)
+	case isMultiple(nm, om):
+		d.narrowed(p, "multipleOf changed from %s to %s", o.MultipleOf, n.MultipleOf)
+	case isMultiple(om, nm):
+		d.widened(p, "multipleOf changed from %s to %s", o.MultipleOf, n.MultipleOf)
+	default:
+		d.changed(p, "multipleOf changed from %s to %s", o.MultipleOf, n.MultipleOf)
+	}
+}
+
+// bound compares a numeric bound. tighter reports whether a is a tighter
+// bound than b.
+func (d *differ) bound(path, keyword string, ov float64, oex, ook bool, nv float64, nex, nok bool, tighter func(a, b float64) bool) {
+	describe := func(v float64, exclusive bool) string {
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if exclusive {
+			s += " (exclusive)"
+		}
+		return s
+	}
+	switch {
+	case !ook && !nok, ook && nok && ov == nv && oex == nex:
+	case !ook:
+		d.narrowed(path, "%s %s added", keyword, describe(nv, nex))
+	case !nok:
+		d.widened(path, "%s %s removed", keyword, describe(ov, oex))
+	case tighter(nv, ov) || (nv == ov && nex):
+		d.narrowed(path, "%s tightened from %s to %s", keyword, describe(ov, oex), describe(nv, nex))
+	default:
+		d.widened(path, "%s relaxed from %s to %s", keyword, describe(ov, oex), describe(nv, nex))
+	}
+}
+
+func (d *differ) strings(path string, o, n *Type) {
+	d.upperLimit(path, "maxLength", o.MaxLength, n.MaxLength)
+	d.lowerLimit(path, "minLength", o.MinLength, n.MinLength)
+	d.constraint(path, "pattern", o.Pattern, n.Pattern)
+	d.constraint(path, "format", o.Format, n.Format)
+	d.constraint(path, "contentEncoding", o.ContentEncoding, n.ContentEncoding)
+	d.constraint(path, "contentMediaType", o.ContentMediaType, n.ContentMediaType)
+}
+
+// upperLimit compares a keyword such as maxLength, for which nil means no limit.
+func (d *differ) upperLimit(path, keyword string, o, n *int) {
+	p := path + "/" + keyword
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.narrowed(p, "%s %d added", keyword, *n)
+	case n == nil:
+		d.widened(p, "%s %d removed", keyword, *o)
+	case *n == *o:
+	case *n < *o:
+		d.narrowed(p, "%s decreased from %d to %d", keyword, *o, *n)
+	default:
+		d.widened(p, "%s increased from %d to %d", keyword, *o, *n)
+	}
+}
+
+// lowerLimit compares a keyword such as minLength.
+func (d *differ) lowerLimit(path, keyword string, o, n int) {
+	p := path + "/" + keyword
+	switch {
+	case o == n:
+	case n > o:
+		d.narrowed(p, "%s increased from %d to %d", keyword, o, n)
+	default:
+		d.widened(p, "%s decreased from %d to %d", keyword, o, n)
+	}
+}
+
+// constraint compares a string keyword such as pattern.
+func (d *differ) constraint(path, keyword string, o, n string) {
+	p := path + "/" + keyword
+	switch {
+	case o == n:
+	case o == "":
+		d.narrowed(p, "%s %q added", keyword, n)
+	case n == "":
+		d.widened(p, "%s %q removed", keyword, o)
+	default:
+		d.changed(p, "%s changed from %q to %q", keyword, o, n)
+	}
+}
+
+func (d *differ) arrays(path string, o, n *Type) {
+	d.upperLimit(path, "maxItems", o.MaxItems, n.MaxItems)
+	d.lowerLimit(path, "minItems", o.MinItems, n.MinItems)
+	switch {
+	case o.UniqueItems == n.UniqueItems:
+	case n.UniqueItems:
+		d.narrowed(path+"/uniqueItems", "uniqueItems added")
+	default:
+		d.widened(path+"/uniqueItems", "uniqueItems removed")
+	}
+
+	switch {
+	case o.TupleItems != nil && n.TupleItems != nil:
+		d.list(path+"/items", o.TupleItems, n.TupleItems, true)
+	case o.TupleItems != nil || n.TupleItems != nil:
+		d.changed(path+"/items", "items changed between a schema and an array of schemas")
+	default:
+		d.lenient(path+"/items", o.Items, n.Items)
+	}
+	d.lenient(path+"/additionalItems", o.AdditionalItems, n.AdditionalItems)
+	d.optional(path+"/contains", "contains", o.Contains, n.Contains)
+}
+
+func (d *differ) objects(path string, o, n *Type) {
+	d.upperLimit(path, "maxProperties", o.MaxProperties, n.MaxProperties)
+	d.lowerLimit(path, "minProperties", o.MinProperties, n.MinProperties)
+
+	for _, name := range n.Required {
+		if !containsString(o.Required, name) {
+			d.narrowed(path+"/required", "property %q is now required", name)
+		}
+	}
+	for _, name := range o.Required {
+		if !containsString(n.Required, name) {
+			d.widened(path+"/required", "property %q is no longer required", name)
+		}
+	}
+
+	oClosed, nClosed := o.closed(), n.closed()
+	for _, name := range n.Properties.Keys() {
+		p := path + "/properties/" + escapePointerToken(name)
+		np, _ := n.Properties.Get(name)
+		if op, ok := o.Properties.Get(name); ok {
+			d.compare(p, op, np)
+		} else if oClosed {
+			d.widened(p, "property %q added", name)
+		} else {
+			d.annotated(p, "property %q added", name)
+		}
+	}
+	for _, name := range o.Properties.Keys() {
+		if _, ok := n.Properties.Get(name); ok {
+			continue
+		}
+		p := path + "/properties/" + escapePointerToken(name)
+		if nClosed {
+			d.narrowed(p, "property %q removed", name)
+		} else {
+			d.annotated(p, "property %q removed", name)
+		}
+	}
+
+	added, removed := d.annotated, d.annotated
+	if oClosed {
+		added = d.widened
+	}
+	if nClosed {
+		removed = d.narrowed
+	}
+	d.schemaMap(path+"/patternProperties", "pattern property", o.PatternProperties, n.PatternProperties, added, removed)
+	d.lenient(path+"/additionalProperties", o.AdditionalProperties, n.AdditionalProperties)
+	d.lenient(path+"/propertyNames", o.PropertyNames, n.PropertyNames)
+	d.schemaMap(path+"/dependencies", "dependency", o.Dependencies, n.Dependencies, d.narrowed, d.widened)
+
+	for _, name := range sortedStringKeys(n.DependentRequired) {
+		for _, required := range n.DependentRequired[name] {
+			if !containsString(o.DependentRequired[name], required) {
+				d.narrowed(path+"/dependencies/"+escapePointerToken(name), "property %q now requires %q", name, required)
+			}
+		}
+	}
+	for _, name := range sortedStringKeys(o.DependentRequired) {
+		for _, required := range o.DependentRequired[name] {
+			if !containsString(n.DependentRequired[name], required) {
+				d.widened(path+"/dependencies/"+escapePointerToken(name), "property %q no longer requires %q", name, required)
+			}
+		}
+	}
+}
+
+// schemaMap compares keywords such as patternProperties, recording added and
+// removed entries with the given functions.
+func (d *differ) schemaMap(path, what string, o, n map[string]*Type, added, removed func(path, format string, args ...interface{})) {
+	for _, key := range sortedKeys(n) {
+		p := path + "/" + escapePointerToken(key)
+		if o[key] != nil {
+			d.compare(p, o[key], n[key])
+		} else {
+			added(p, "%s %q added", what, key)
+		}
+	}
+	for _, key := range sortedKeys(o) {
+		if n[key] == nil {
+			removed(path+"/"+escapePointerToken(key), "%s %q removed", what, key)
+		}
+	}
+}
+
+func (d *differ) combinators(path string, o, n *Type) {
+	d.list(path+"/allOf", o.AllOf, n.AllOf, true)
+	d.list(path+"/anyOf", o.AnyOf, n.AnyOf, false)
+	d.list(path+"/oneOf", o.OneOf, n.OneOf, false)
+	d.opaque(path+"/not", "not", o.Not, n.Not)
+	d.opaque(path+"/if", "if", o.If, n.If)
+	d.opaque(path+"/then", "then", o.Then, n.Then)
+	d.opaque(path+"/else", "else", o.Else, n.Else)
+}
+
+// list compares schemas by position. Added schemas narrow the schema if
+// conjunctive, as for allOf, and widen it otherwise, as for anyOf.
+func (d *differ) list(path string, o, n []*Type, conjunctive bool) {
+	for i := 0; i < len(o) || i < len(n); i++ {
+		p := path + "/" + strconv.Itoa(i)
+		switch {
+		case i < len(o) && i < len(n):
+			d.compare(p, o[i], n[i])
+		case i < len(n) && conjunctive, i < len(o) && !conjunctive:
+			d.narrowed(p, "schema %s", addedOrRemoved(i < len(n)))
+		default:
+			d.widened(p, "schema %s", addedOrRemoved(i < len(n)))
+		}
+	}
+}
+
+// lenient compares keywords such as additionalProperties, which are
+// equivalent to true when absent.
+func (d *differ) lenient(path string, o, n *Type) {
+	if o == nil && n == nil {
+		return
+	}
+	if o == nil {
+		o = TrueSchema()
+	}
+	if n == nil {
+		n = TrueSchema()
+	}
+	d.compare(path, o, n)
+}
+
+// optional compares keywords such as contains, which constrain the instance
+// when present.
+func (d *differ) optional(path, keyword string, o, n *Type) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil:
+		d.narrowed(path, "%s added", keyword)
+	case n == nil:
+		d.widened(path, "%s removed", keyword)
+	default:
+		d.compare(path, o, n)
+	}
+}
+
+// opaque compares keywords such as not, for which any change may both
+// narrow and widen the schema.
+func (d *differ) opaque(path, keyword string, o, n *Type) {
+	switch {
+	case o == nil && n == nil:
+	case o == nil || n == nil:
+		d.changed(path, "%s %s", keyword, addedOrRemoved(n != nil))
+	default:
+		od, _ := json.Marshal(o)
+		nd, _ := json.Marshal(n)
+		if string(od) != string(nd) {
+			d.changed(path, "%s changed", keyword)
+		}
+	}
+}
+
+func (d *differ) annotations(path string, o, n *Type) {
+	text := func(keyword, o, n string) {
+		if o != n {
+			d.annotated(path+"/"+keyword, "%s changed", keyword)
+		}
+	}
+	text("title", o.Title, n.Title)
+	text("description", o.Description, n.Description)
+	text("$comment", o.Comment, n.Comment)
+	if o.HasDefault() != n.HasDefault() || !equalJSON(o.Default, n.Default) {
+		d.annotated(path+"/default", "default changed from %s to %s", canonicalJSON(o.Default), canonicalJSON(n.Default))
+	}
+	if !equalJSON(o.Examples, n.Examples) {
+		d.annotated(path+"/examples", "examples changed")
+	}
+	if o.ReadOnly != n.ReadOnly {
+		d.annotated(path+"/readOnly", "readOnly changed to %t", n.ReadOnly)
+	}
+	if o.WriteOnly != n.WriteOnly {
+		d.annotated(path+"/writeOnly", "writeOnly changed to %t", n.WriteOnly)
+	}
+}
+
+// typeList returns the types allowed by t, or nil if it allows any.
+func (t *Type) typeList() []string {
+	if len(t.Types) > 0 {
+		return t.Types
+	}
+	if t.Type != "" {
+		return []string{t.Type}
+	}
+	return nil
+}
+
+// closed reports whether t disallows properties other than those it declares.
+func (t *Type) closed() bool {
+	if t.AdditionalProperties == nil {
+		return false
+	}
+	value, ok := t.AdditionalProperties.Boolean()
+	return ok && !value
+}
+
+// coversType reports whether types allows instances of typ.
+func coversType(types []string, typ string) bool {
+	return containsString(types, typ) || (typ == "integer" && containsString(types, "number"))
+}
+
+func isMultiple(a, b float64) bool {
+	if b == 0 {
+		return false
+	}
+	q := a / b
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
+func addedOrRemoved(added bool) string {
+	if added {
+		return "added"
+	}
+	return "removed"
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsJSON(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if equalJSON(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedStringKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type OrderV1 struct {
+	ID    string   `json:"id" jsonschema:"maxLength=32"`
+	Note  string   `json:"note,omitempty" jsonschema:"description=free text"`
+	Items []string `json:"items,omitempty"`
+	Total float64  `json:"total,omitempty" jsonschema:"minimum=0"`
+}
+
+type OrderV2 struct {
+	ID       string  `json:"id" jsonschema:"maxLength=16"`
+	Note     string  `json:"note,omitempty" jsonschema:"description=free form text"`
+	Items    []int   `json:"items,omitempty"`
+	Total    float64 `json:"total,omitempty" jsonschema:"minimum=-10"`
+	Currency string  `json:"currency"`
+}
+
+func TestDiff(t *testing.T) {
+	old := (&Reflector{}).Reflect(&OrderV1{})
+	new := (&Reflector{}).Reflect(&OrderV2{})
+	// The definitions are named after the Go types, so compare them as the
+	// same definition.
+	old.Type.Ref = "#/definitions/Order"
+	old.Definitions = Definitions{"Order": old.Definitions["OrderV1"]}
+	new.Type.Ref = "#/definitions/Order"
+	new.Definitions = Definitions{"Order": new.Definitions["OrderV2"]}
+
+	var actual []string
+	for _, change := range Diff(old, new) {
+		actual = append(actual, change.String())
+	}
+	require.Equal(t, []string{
+		`/definitions/Order/required: property "currency" is now required (breaks producers)`,
+		`/definitions/Order/properties/id/maxLength: maxLength decreased from 32 to 16 (breaks producers)`,
+		`/definitions/Order/properties/note/description: description changed (non-breaking)`,
+		`/definitions/Order/properties/items/items/type: type string no longer allowed (breaks producers)`,
+		`/definitions/Order/properties/items/items/type: type integer now allowed (breaks consumers)`,
+		`/definitions/Order/properties/total/minimum: minimum relaxed from 0 to -10 (breaks consumers)`,
+		`/definitions/Order/properties/currency: property "currency" added (breaks consumers)`,
+	}, actual)
+}
+
+func TestDiffKeywords(t *testing.T) {
+	tests := []struct {
+		old, new string
+		expected []string
+	}{
+		{`{"additionalProperties": true}`, `{"additionalProperties": false}`,
+			[]string{"/additionalProperties: schema changed from true to false (breaks producers)"}},
+		{`{}`, `{"additionalProperties": false}`,
+			[]string{"/additionalProperties: schema changed from true to false (breaks producers)"}},
+		{`{"type": "integer"}`, `{"type": ["number", "null"]}`,
+			[]string{"/type: type number now allowed (breaks consumers)", "/type: type null now allowed (breaks consumers)"}},
+		{`{"maximum": 10}`, `{"maximum": 10, "exclusiveMaximum": true}`,
+			[]string{"/maximum: maximum tightened from 10 to 10 (exclusive) (breaks producers)"}},
+		{`{"exclusiveMaximum": 10}`, `{"maximum": 10}`,
+			[]string{"/maximum: maximum relaxed from 10 (exclusive) to 10 (breaks consumers)"}},
+		{`{"multipleOf": 2}`, `{"multipleOf": 4}`,
+			[]string{"/multipleOf: multipleOf changed from 2 to 4 (breaks producers)"}},
+		{`{"pattern": "^a"}`, `{"pattern": "^b"}`,
+			[]string{`/pattern: pattern changed from "^a" to "^b" (breaks producers and consumers)`}},
+		{`{"anyOf": [{"type": "string"}]}`, `{"anyOf": [{"type": "string"}, {"type": "null"}]}`,
+			[]string{"/anyOf/1: schema added (breaks consumers)"}},
+		{`{"properties": {"a": {"$ref": "#/definitions/A"}}, "definitions": {"A": {"minLength": 1}, "B": {"minLength": 2}}}`,
+			`{"properties": {"a": {"$ref": "#/definitions/B"}}, "definitions": {"A": {"minLength": 1}, "B": {"minLength": 2}}}`,
+			[]string{
+				`/properties/a/$ref: $ref changed from "#/definitions/A" to "#/definitions/B" (non-breaking)`,
+				"/properties/a/minLength: minLength increased from 1 to 2 (breaks producers)",
+			}},
+		{`{"enum": ["open", "closed"]}`, `{"enum": ["open", "pending"]}`,
+			[]string{`/enum: enum value "closed" removed (breaks producers)`, `/enum: enum value "pending" added (breaks consumers)`}},
+		{`{"dependencies": {"a": ["b"]}}`, `{"dependencies": {"a": ["b", "c"]}}`,
+			[]string{`/dependencies/a: property "a" now requires "c" (breaks producers)`}},
+	}
+	for _, tt := range tests {
+		var actual []string
+		for _, change := range Diff(mustSchema(t, tt.old), mustSchema(t, tt.new)) {
+			actual = append(actual, change.String())
+		}
+		require.Equal(t, tt.expected, actual, "%s -> %s", tt.old, tt.new)
+	}
+}
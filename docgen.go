@@ -0,0 +1,462 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MarkdownGenerator generates reference documentation in Markdown for the
+// types described by a schema.
+//
+// The documentation has a section for the root schema, unless it refers to a
+// definition, and one for each definition. A section has the description of
+// the schema, a table of its properties with their type, whether they are
+// required, their default, their constraints and their description, and the
+// examples of the schema. Properties of objects nested in a property are
+// listed after it, as "parent.child", and those of array items as
+// "parent[].child". References to definitions link to their section.
+type MarkdownGenerator struct {
+	// Title is the title of the document. It defaults to the title of the
+	// schema, or "Schema".
+	Title string
+}
+
+// Generate returns Markdown documentation for s.
+func (g *MarkdownGenerator) Generate(s *Schema) ([]byte, error) {
+	doc := newDocModel(s, g.Title)
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "# %s\n", doc.Title)
+	for _, section := range doc.Sections {
+		fmt.Fprintf(out, "\n<a id=\"%s\"></a>\n\n## %s\n", section.Anchor, section.Name)
+		if section.Description != "" {
+			fmt.Fprintf(out, "\n%s\n", section.Description)
+		}
+		if len(section.Type) > 0 {
+			fmt.Fprintf(out, "\nType: %s\n", markdownParts(section.Type))
+		}
+		if len(section.Constraints) > 0 {
+			fmt.Fprintf(out, "\nConstraints: %s\n", markdownConstraints(section.Constraints))
+		}
+		if len(section.Properties) > 0 {
+			out.WriteString("\n| Property | Type | Required | Default | Constraints | Description |\n")
+			out.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+			for _, p := range section.Properties {
+				required := "no"
+				if p.Required {
+					required = "yes"
+				}
+				description := markdownCell(p.Description)
+				if len(p.Examples) > 0 {
+					if description != "" {
+						description += "<br>"
+					}
+					description += "Examples: " + markdownCell(markdownCodes(p.Examples...))
+				}
+				fmt.Fprintf(out, "| `%s` | %s | %s | %s | %s | %s |\n",
+					markdownCell(p.Name), markdownCell(markdownParts(p.Type)), required,
+					markdownCell(markdownCodes(p.Default)), markdownCell(markdownConstraints(p.Constraints)), description)
+			}
+		}
+		if len(section.Examples) > 0 {
+			out.WriteString("\nExamples:\n")
+			for _, example := range section.Examples {
+				fmt.Fprintf(out, "\n```json\n%s\n```\n", example)
+			}
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// HTMLGenerator generates reference documentation as a standalone HTML page
+// for the types described by a schema, with the same content as the
+// MarkdownGenerator.
+type HTMLGenerator struct {
+	// Title is the title of the page. It defaults to the title of the schema,
+	// or "Schema".
+	Title string
+}
+
+// Generate returns an HTML page documenting s.
+func (g *HTMLGenerator) Generate(s *Schema) ([]byte, error) {
+	doc := newDocModel(s, g.Title)
+	out := &bytes.Buffer{}
+	if err := htmlDocTemplate.Execute(out, doc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+var htmlDocTemplate = template.Must(template.New("doc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+code, pre { background: #f5f5f5; }
+pre { padding: 0.6em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+{{- range .Sections }}
+<section id="{{ .Anchor }}">
+<h2>{{ .Name }}</h2>
+{{- if .Description }}
+<p>{{ .Description }}</p>
+{{- end }}
+{{- if .Type }}
+<p>Type: {{ template "parts" .Type }}</p>
+{{- end }}
+{{- if .Constraints }}
+<p>Constraints: {{ template "constraints" .Constraints }}</p>
+{{- end }}
+{{- if .Properties }}
+<table>
+<tr><th>Property</th><th>Type</th><th>Required</th><th>Default</th><th>Constraints</th><th>Description</th></tr>
+{{- range .Properties }}
+<tr><td><code>{{ .Name }}</code></td><td>{{ template "parts" .Type }}</td><td>{{ if .Required }}yes{{ else }}no{{ end }}</td><td>{{ if .Default }}<code>{{ .Default }}</code>{{ end }}</td><td>{{ template "constraints" .Constraints }}</td><td>{{ .Description }}{{ if .Examples }}{{ if .Description }}<br>{{ end }}Examples: {{ range $i, $e := .Examples }}{{ if $i }}, {{ end }}<code>{{ $e }}</code>{{ end }}{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- if .Examples }}
+<p>Examples:</p>
+{{- range .Examples }}
+<pre><code>{{ . }}</code></pre>
+{{- end }}
+{{- end }}
+</section>
+{{- end }}
+</body>
+</html>
+{{ define "parts" }}{{ range . }}{{ if .Anchor }}<a href="#{{ .Anchor }}">{{ .Text }}</a>{{ else }}{{ .Text }}{{ end }}{{ end }}{{ end }}
+{{- define "constraints" }}{{ range $i, $c := . }}{{ if $i }}, {{ end }}{{ $c.Keyword }}{{ range $j, $v := $c.Values }}{{ if $j }},{{ else }}:{{ end }} <code>{{ $v }}</code>{{ end }}{{ end }}{{ end }}`))
+
+// docModel is the content of the documentation of a schema.
+type docModel struct {
+	Title    string
+	Sections []*docSection
+}
+
+// docSection documents the root schema or a definition.
+type docSection struct {
+	Name        string
+	Anchor      string
+	Description string
+	Type        []docPart
+	Constraints []docConstraint
+	Properties  []*docProperty
+	Examples    []string
+}
+
+// docProperty is a row of a property table.
+type docProperty struct {
+	Name        string
+	Type        []docPart
+	Required    bool
+	Default     string
+	Constraints []docConstraint
+	Description string
+	Examples    []string
+}
+
+// docPart is part of a type, linking to the section of a definition if
+// Anchor is set.
+type docPart struct {
+	Text   string
+	Anchor string
+}
+
+// docConstraint is a keyword with its values, in JSON.
+type docConstraint struct {
+	Keyword string
+	Values  []string
+}
+
+func newDocModel(s *Schema, title string) *docModel {
+	d := &docModel{Title: title}
+	if d.Title == "" && s.Type != nil {
+		d.Title = s.Type.Title
+	}
+	if d.Title == "" {
+		d.Title = "Schema"
+	}
+	b := &docBuilder{anchors: map[string]string{}}
+	definitions := mergedDefinitions(s)
+	used := map[string]bool{}
+	names := sortedKeys(definitions)
+	for _, name := range names {
+		anchor := docAnchor(name)
+		for i := 2; used[anchor]; i++ {
+			anchor = docAnchor(name) + "-" + strconv.Itoa(i)
+		}
+		used[anchor] = true
+		b.anchors[name] = anchor
+	}
+	if s.Type != nil {
+		if _, ok := definitionRef(s.Type.Ref); !ok {
+			anchor := "root"
+			for i := 2; used[anchor]; i++ {
+				anchor = "root-" + strconv.Itoa(i)
+			}
+			d.Sections = append(d.Sections, b.section(d.Title, anchor, s.Type))
+		}
+	}
+	for _, name := range names {
+		d.Sections = append(d.Sections, b.section(name, b.anchors[name], definitions[name]))
+	}
+	return d
+}
+
+type docBuilder struct {
+	anchors map[string]string // section anchors by definition name
+}
+
+// section documents a schema.
+func (b *docBuilder) section(name, anchor string, t *Type) *docSection {
+	section := &docSection{
+		Name:        name,
+		Anchor:      anchor,
+		Description: t.Description,
+		Type:        b.typeParts(t),
+		Constraints: docConstraints(t),
+		Examples:    indentedJSON(t.Examples),
+	}
+	b.properties(section, "", t)
+	return section
+}
+
+// properties adds the properties of t to the table of section, recursing
+// into nested objects and array items.
+func (b *docBuilder) properties(section *docSection, prefix string, t *Type) {
+	for _, name := range t.Properties.Keys() {
+		sub, _ := t.Properties.Get(name)
+		p := &docProperty{
+			Name:        prefix + name,
+			Type:        b.typeParts(sub),
+			Required:    containsString(t.Required, name),
+			Constraints: docConstraints(sub),
+			Description: sub.Description,
+			Examples:    compactJSON(sub.Examples),
+		}
+		if sub.HasDefault() {
+			p.Default = canonicalJSON(sub.Default)
+		}
+		section.Properties = append(section.Properties, p)
+		if sub.Ref != "" {
+			continue
+		}
+		b.properties(section, prefix+name+".", sub)
+		if sub.Items != nil && sub.Items.Ref == "" {
+			b.properties(section, prefix+name+"[].", sub.Items)
+		}
+	}
+}
+
+// typeParts describes the type of t, linking to the definitions it refers to.
+func (b *docBuilder) typeParts(t *Type) []docPart {
+	if allowed, ok := t.Boolean(); ok {
+		if allowed {
+			return []docPart{{Text: "any"}}
+		}
+		return []docPart{{Text: "nothing"}}
+	}
+	if t.Ref != "" {
+		if name, ok := definitionRef(t.Ref); ok {
+			if anchor, ok := b.anchors[name]; ok {
+				return []docPart{{Text: name, Anchor: anchor}}
+			}
+		}
+		return []docPart{{Text: t.Ref}}
+	}
+	var alternatives [][]docPart
+	for _, typ := range t.typeList() {
+		switch typ {
+		case "array":
+			if t.Items != nil {
+				alternatives = append(alternatives, append([]docPart{{Text: "array of "}}, b.typeParts(t.Items)...))
+			} else {
+				alternatives = append(alternatives, []docPart{{Text: "array"}})
+			}
+		case "string":
+			if t.Format != "" {
+				typ += " (" + t.Format + ")"
+			}
+			alternatives = append(alternatives, []docPart{{Text: typ}})
+		default:
+			alternatives = append(alternatives, []docPart{{Text: typ}})
+		}
+	}
+	for _, list := range []struct {
+		subs      []*Type
+		separator string
+	}{{t.OneOf, " or "}, {t.AnyOf, " or "}, {t.AllOf, " and "}} {
+		if len(list.subs) == 0 {
+			continue
+		}
+		var parts []docPart
+		for i, sub := range list.subs {
+			if i > 0 {
+				parts = append(parts, docPart{Text: list.separator})
+			}
+			parts = append(parts, b.typeParts(sub)...)
+		}
+		alternatives = append(alternatives, parts)
+	}
+	var parts []docPart
+	for i, alternative := range alternatives {
+		if i > 0 {
+			parts = append(parts, docPart{Text: " or "})
+		}
+		parts = append(parts, alternative...)
+	}
+	return parts
+}
+
+// docConstraints returns the validation keywords of t.
+func docConstraints(t *Type) []docConstraint {
+	var constraints []docConstraint
+	add := func(keyword string, values ...interface{}) {
+		c := docConstraint{Keyword: keyword}
+		for _, value := range values {
+			c.Values = append(c.Values, canonicalJSON(value))
+		}
+		constraints = append(constraints, c)
+	}
+	if len(t.Enum) > 0 {
+		add("enum", t.Enum...)
+	}
+	if t.HasConst() {
+		add("const", t.Const)
+	}
+	if t.MultipleOf != "" {
+		add("multipleOf", t.MultipleOf)
+	}
+	if t.Minimum != "" {
+		add("minimum", t.Minimum)
+	}
+	if len(t.ExclusiveMinimum) > 0 {
+		add("exclusiveMinimum", t.ExclusiveMinimum)
+	}
+	if t.Maximum != "" {
+		add("maximum", t.Maximum)
+	}
+	if len(t.ExclusiveMaximum) > 0 {
+		add("exclusiveMaximum", t.ExclusiveMaximum)
+	}
+	if t.MinLength > 0 {
+		add("minLength", t.MinLength)
+	}
+	if t.MaxLength != nil {
+		add("maxLength", *t.MaxLength)
+	}
+	if t.Pattern != "" {
+		add("pattern", t.Pattern)
+	}
+	if t.MinItems > 0 {
+		add("minItems", t.MinItems)
+	}
+	if t.MaxItems != nil {
+		add("maxItems", *t.MaxItems)
+	}
+	if t.UniqueItems {
+		add("uniqueItems", true)
+	}
+	if t.MinProperties > 0 {
+		add("minProperties", t.MinProperties)
+	}
+	if t.MaxProperties != nil {
+		add("maxProperties", *t.MaxProperties)
+	}
+	if t.ReadOnly {
+		add("readOnly", true)
+	}
+	if t.WriteOnly {
+		add("writeOnly", true)
+	}
+	return constraints
+}
+
+// indentedJSON returns the indented JSON encodings of values.
+func indentedJSON(values []interface{}) []string {
+	var encoded []string
+	for _, value := range values {
+		out := &bytes.Buffer{}
+		if err := json.Indent(out, []byte(canonicalJSON(value)), "", "  "); err == nil {
+			encoded = append(encoded, out.String())
+		}
+	}
+	return encoded
+}
+
+// compactJSON returns the JSON encodings of values.
+func compactJSON(values []interface{}) []string {
+	var encoded []string
+	for _, value := range values {
+		encoded = append(encoded, canonicalJSON(value))
+	}
+	return encoded
+}
+
+var nonAnchor = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// docAnchor returns an HTML id for the section of a definition.
+func docAnchor(name string) string {
+	anchor := strings.Trim(nonAnchor.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if anchor == "" {
+		anchor = "definition"
+	}
+	return anchor
+}
+
+func markdownParts(parts []docPart) string {
+	b := &strings.Builder{}
+	for _, part := range parts {
+		if part.Anchor != "" {
+			fmt.Fprintf(b, "[%s](#%s)", part.Text, part.Anchor)
+		} else {
+			b.WriteString(part.Text)
+		}
+	}
+	return b.String()
+}
+
+func markdownConstraints(constraints []docConstraint) string {
+	formatted := make([]string, len(constraints))
+	for i, c := range constraints {
+		formatted[i] = c.Keyword + ": " + markdownCodes(c.Values...)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// markdownCodes formats values as code spans.
+func markdownCodes(values ...string) string {
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		fence := "`"
+		for strings.Contains(value, fence) {
+			fence += "`"
+		}
+		if strings.HasPrefix(value, "`") || strings.HasSuffix(value, "`") {
+			value = " " + value + " "
+		}
+		formatted = append(formatted, fence+value+fence)
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// markdownCell escapes text for a table cell.
+func markdownCell(text string) string {
+	text = strings.Replace(text, "|", "\\|", -1)
+	return strings.Replace(strings.TrimSpace(text), "\n", "<br>", -1)
+}
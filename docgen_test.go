@@ -0,0 +1,49 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocGenerators(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/gogen.json")
+	require.NoError(t, err)
+	order := &Schema{}
+	require.NoError(t, json.Unmarshal(data, order))
+	data, err = ioutil.ReadFile("fixtures/docgen.json")
+	require.NoError(t, err)
+	pipeline := &Schema{}
+	require.NoError(t, json.Unmarshal(data, pipeline))
+
+	tests := []struct {
+		generator interface {
+			Generate(*Schema) ([]byte, error)
+		}
+		schema  *Schema
+		fixture string
+	}{
+		{&MarkdownGenerator{}, order, "fixtures/order.md.golden"},
+		{&HTMLGenerator{}, order, "fixtures/order.html.golden"},
+		{&MarkdownGenerator{Title: "Users"}, Reflect(&TestUser{}), "fixtures/test_user.md.golden"},
+		{&MarkdownGenerator{}, pipeline, "fixtures/docgen.md.golden"},
+		{&HTMLGenerator{}, pipeline, "fixtures/docgen.html.golden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			actual, err := tt.generator.Generate(tt.schema)
+			require.NoError(t, err)
+			expected, err := ioutil.ReadFile(tt.fixture)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestMarkdownCodes(t *testing.T) {
+	require.Equal(t, "`a`, ``` `` ```", markdownCodes("a", "``"))
+	require.Equal(t, "`a\\|b`", markdownCell("`a|b`"))
+	require.Equal(t, "`\"a\\|b\"`, `null`", markdownCell(markdownCodes(`"a|b"`, "null")))
+}
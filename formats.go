@@ -0,0 +1,220 @@
+package jsonschema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// formats holds the checkers of the registered formats, by name. Values of
+// other formats are not checked.
+// RFC draft-handrews-json-schema-validation-01, section 7.3
+var formats = map[string]func(string) bool{
+	"date-time":             isDateTime,
+	"date":                  isDate,
+	"time":                  isTime,
+	"duration":              isDuration,
+	"email":                 isEmail,
+	"idn-email":             isIDNEmail,
+	"hostname":              isHostname,
+	"idn-hostname":          isIDNHostname,
+	"ipv4":                  isIPv4,
+	"ipv6":                  isIPv6,
+	"uri":                   isURI,
+	"uri-reference":         isURIReference,
+	"iri":                   isIRI,
+	"iri-reference":         isIRIReference,
+	"json-pointer":          isJSONPointer,
+	"relative-json-pointer": isRelativeJSONPointer,
+	"regex":                 isRegex,
+	"uuid":                  isUUID,
+}
+
+var formatsMu sync.RWMutex
+
+// RegisterFormat registers check as the checker of the format name,
+// replacing any previous checker, including a built-in one. Values of the
+// format are then checked by validation, and a Resolver with StrictFormats
+// accepts it. Validators compiled before the format is registered do not
+// check it.
+func RegisterFormat(name string, check func(string) bool) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[name] = check
+}
+
+// lookupFormat returns the checker of a format, if it is registered.
+func lookupFormat(name string) (func(string) bool, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	check, ok := formats[name]
+	return check, ok
+}
+
+// isDateTime reports whether s is an RFC 3339 date-time.
+func isDateTime(s string) bool {
+	i := strings.IndexAny(s, "Tt")
+	return i >= 0 && isDate(s[:i]) && isTime(s[i+1:])
+}
+
+// isDate reports whether s is an RFC 3339 full-date.
+func isDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+var timePattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):([0-5][0-9]):([0-5][0-9]|60)(\.[0-9]+)?([Zz]|[+-]([01][0-9]|2[0-3]):[0-5][0-9])$`)
+
+// isTime reports whether s is an RFC 3339 full-time.
+func isTime(s string) bool {
+	return timePattern.MatchString(s)
+}
+
+var durationPattern = regexp.MustCompile(`^P(?:(?:[0-9]+D|[0-9]+M(?:[0-9]+D)?|[0-9]+Y(?:[0-9]+M(?:[0-9]+D)?)?)(?:T(?:[0-9]+H(?:[0-9]+M(?:[0-9]+S)?)?|[0-9]+M(?:[0-9]+S)?|[0-9]+S))?|T(?:[0-9]+H(?:[0-9]+M(?:[0-9]+S)?)?|[0-9]+M(?:[0-9]+S)?|[0-9]+S)|[0-9]+W)$`)
+
+// isDuration reports whether s is an RFC 3339 duration, as defined in its
+// appendix A.
+func isDuration(s string) bool {
+	return durationPattern.MatchString(s)
+}
+
+// isEmail reports whether s is an RFC 5322 addr-spec.
+func isEmail(s string) bool {
+	return isASCII(s) && isIDNEmail(s)
+}
+
+// isIDNEmail reports whether s is an RFC 6531 addr-spec, which may contain
+// non-ASCII characters.
+func isIDNEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && addr.Name == ""
+}
+
+var hostnameLabel = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?$`)
+
+// isHostname reports whether s is an RFC 1123 hostname.
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if len(label) > 63 || !hostnameLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIDNHostname reports whether s is an RFC 5890 internationalized
+// hostname: labels of letters, marks, digits and hyphens, of at most 63
+// characters each, that do not start or end with a hyphen nor start with a
+// mark. The IDNA2008 tables of disallowed code points are not checked.
+func isIDNHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || utf8.RuneCountInString(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || utf8.RuneCountInString(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		if first, _ := utf8.DecodeRuneInString(label); unicode.IsMark(first) {
+			return false
+		}
+		for _, r := range label {
+			if r != '-' && !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsDigit(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isIPv4 reports whether s is an IPv4 address in dotted-quad notation.
+func isIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+}
+
+// isIPv6 reports whether s is an RFC 4291 IPv6 address.
+func isIPv6(s string) bool {
+	return net.ParseIP(s) != nil && strings.Contains(s, ":")
+}
+
+// isURI reports whether s is an absolute RFC 3986 URI.
+func isURI(s string) bool {
+	return isASCII(s) && isIRI(s)
+}
+
+// isURIReference reports whether s is an RFC 3986 URI reference.
+func isURIReference(s string) bool {
+	return isASCII(s) && isIRIReference(s)
+}
+
+// isIRI reports whether s is an absolute RFC 3987 IRI, which may contain
+// non-ASCII characters.
+func isIRI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs() && !strings.ContainsAny(s, " \\")
+}
+
+// isIRIReference reports whether s is an RFC 3987 IRI reference.
+func isIRIReference(s string) bool {
+	_, err := url.Parse(s)
+	return err == nil && !strings.ContainsAny(s, " \\")
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// isJSONPointer reports whether s is an RFC 6901 JSON Pointer.
+func isJSONPointer(s string) bool {
+	if s != "" && !strings.HasPrefix(s, "/") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '~' && (i+1 == len(s) || (s[i+1] != '0' && s[i+1] != '1')) {
+			return false
+		}
+	}
+	return true
+}
+
+var relativeJSONPointerPrefix = regexp.MustCompile(`^(0|[1-9][0-9]*)`)
+
+// isRelativeJSONPointer reports whether s is a relative JSON Pointer.
+func isRelativeJSONPointer(s string) bool {
+	prefix := relativeJSONPointerPrefix.FindString(s)
+	if prefix == "" {
+		return false
+	}
+	rest := s[len(prefix):]
+	return rest == "#" || isJSONPointer(rest)
+}
+
+// isRegex reports whether s is a regular expression. Patterns are matched
+// with the regexp package, so the regular expressions it accepts are valid.
+func isRegex(s string) bool {
+	_, err := compilePattern(s)
+	return err == nil
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+
+// isUUID reports whether s is an RFC 4122 UUID.
+func isUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
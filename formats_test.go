@@ -0,0 +1,106 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		valid  bool
+	}{
+		{"date-time", "2020-01-02T03:04:05.6+01:00", true},
+		{"date-time", "2020-01-02 03:04:05", false},
+		{"date", "2020-02-30", false},
+		{"time", "24:00:00Z", false},
+		{"duration", "P1Y2M3DT4H5M6S", true},
+		{"duration", "PT36H", true},
+		{"duration", "P2W", true},
+		{"duration", "P1DT", false},
+		{"duration", "P1Y2W", false},
+		{"email", "joe@example.com", true},
+		{"email", "用户@例子.广告", false},
+		{"idn-email", "用户@例子.广告", true},
+		{"idn-email", "Joe <joe@example.com>", false},
+		{"hostname", "example.com", true},
+		{"hostname", "-example.com", false},
+		{"idn-hostname", "例子.广告", true},
+		{"idn-hostname", "例子_.广告", false},
+		{"ipv4", "192.168.0.1", true},
+		{"ipv4", "::1", false},
+		{"ipv6", "::1", true},
+		{"uri", "https://example.com/a?b#c", true},
+		{"uri", "/a", false},
+		{"uri", "https://例子.广告/", false},
+		{"uri-reference", "/a", true},
+		{"iri", "https://例子.广告/ü", true},
+		{"iri", "例子", false},
+		{"iri-reference", "ü#x", true},
+		{"json-pointer", "/a~1b", true},
+		{"json-pointer", "/a~2", false},
+		{"relative-json-pointer", "1/a", true},
+		{"regex", "^a+$", true},
+		{"regex", "(", false},
+		{"uuid", "0b0a8a4e-7c0e-4c5e-9e6a-6f1a1c7e2b3d", true},
+		{"uuid", "0b0a8a4e7c0e4c5e9e6a6f1a1c7e2b3d", false},
+	}
+	for _, test := range tests {
+		t.Run(test.format+" "+test.value, func(t *testing.T) {
+			check, ok := lookupFormat(test.format)
+			require.True(t, ok)
+			require.Equal(t, test.valid, check(test.value))
+		})
+	}
+}
+
+type EvenCode struct {
+	Code     string `json:"code" jsonschema:"format=x-even-length"`
+	ID       string `json:"id" jsonschema:"format=uuid"`
+	Duration string `json:"duration" jsonschema:"format=duration"`
+	Other    string `json:"other" jsonschema:"format=x-unregistered"`
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("x-even-length", func(s string) bool { return len(s)%2 == 0 })
+
+	s := (&Reflector{ExpandedStruct: true}).Reflect(&EvenCode{})
+	format := func(name string) string {
+		property, _ := s.Properties.Get(name)
+		return property.Format
+	}
+	require.Equal(t, "x-even-length", format("code"))
+	require.Equal(t, "uuid", format("id"))
+	require.Equal(t, "duration", format("duration"))
+	require.Equal(t, "x-unregistered", format("other"))
+
+	valid := []byte(`{"code": "ab", "id": "0b0a8a4e-7c0e-4c5e-9e6a-6f1a1c7e2b3d", "duration": "P1D", "other": ""}`)
+	err := s.ValidateJSON([]byte(`{"code": "abc", "id": "0b0a8a4e-7c0e-4c5e-9e6a-6f1a1c7e2b3d", "duration": "P1D", "other": ""}`))
+	require.EqualError(t, err, `/code: "abc" is not a valid x-even-length`)
+	require.NoError(t, s.ValidateJSON(valid))
+
+	// With StrictFormats, the unknown format is an error of the compiled
+	// validator and a failure of the interpreting one alike.
+	r := NewResolver(nil)
+	r.StrictFormats = true
+	require.NoError(t, r.AddSchema("", s))
+	_, err = r.Compile(s.Type)
+	require.EqualError(t, err, `jsonschema: /properties/other/format: unknown format "x-unregistered"`)
+	var instance interface{}
+	require.NoError(t, decodeJSON(valid, &instance))
+	require.EqualError(t, r.Validate(s.Type, instance), `/other: unknown format "x-unregistered"`)
+
+	r.StrictFormats = false
+	v, err := r.Compile(s.Type)
+	require.NoError(t, err)
+	require.NoError(t, v.ValidateJSON(valid))
+	require.NoError(t, r.Validate(s.Type, instance))
+
+	var code EvenCode
+	strict := &Reflector{ExpandedStruct: true, Resolver: &Resolver{StrictFormats: true}}
+	require.EqualError(t, strict.Unmarshal(valid, &code), `jsonschema: /properties/other/format: unknown format "x-unregistered"`)
+	require.EqualError(t, strict.ValidateValue(&code), `jsonschema: /properties/other/format: unknown format "x-unregistered"`)
+	require.NoError(t, (&Reflector{ExpandedStruct: true}).Unmarshal(valid, &code))
+}
@@ -0,0 +1,262 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A FuzzCase is an instance generated by a Fuzzer: either a valid instance of
+// the schema, or a valid instance mutated to violate one of its keywords.
+type FuzzCase struct {
+	// Instance is the instance, as a value decoded from JSON would be.
+	Instance interface{}
+	// Keyword is the keyword the instance violates, such as "required" or
+	// "maxLength", or "" if the instance is valid.
+	Keyword string
+	// Path is the JSON Pointer of the value of the instance that violates
+	// Keyword. It is "" for valid instances as well as for the root.
+	Path string
+}
+
+// Valid reports whether the instance of c is valid.
+func (c FuzzCase) Valid() bool {
+	return c.Keyword == ""
+}
+
+// A FuzzCorpus is a seed corpus of a fuzz test. *testing.F implements it.
+type FuzzCorpus interface {
+	Add(args ...interface{})
+}
+
+// Fuzzer generates inputs for property-based and fuzz tests of code that
+// handles instances of a schema, such as HTTP handlers.
+//
+// Valid instances are samples of the schema, as SampleGenerator generates
+// them. Invalid instances are valid ones with a targeted mutation: a required
+// property dropped, a property added to an object whose additionalProperties
+// is false, a value of a type, enum or const the schema does not allow, or a
+// length, number of items or number beyond a bound. A mutation is only kept
+// if validation reports the failure of the keyword it targets at the mutated
+// value, so that every invalid case is labeled with the keyword it violates.
+type Fuzzer struct {
+	// Seed seeds the random choices of the fuzzer. The same seed yields the
+	// same cases of the same schema.
+	Seed int64
+
+	// MaxDepth is the depth of nesting of the instances, as in
+	// SampleGenerator. It defaults to 4.
+	MaxDepth int
+}
+
+// Generate returns n cases of s, alternating valid and invalid instances,
+// starting with a valid one. Valid instances are used in place of invalid
+// ones when no mutation of an instance violates the schema.
+func (f *Fuzzer) Generate(s *Schema, n int) ([]FuzzCase, error) {
+	gen, err := newSampler(s, f.Seed, f.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]FuzzCase, 0, n)
+	for i := 0; i < n; i++ {
+		valid, err := gen.validSample(s.Type)
+		if err != nil {
+			return nil, err
+		}
+		c := FuzzCase{Instance: valid}
+		if i%2 == 1 {
+			if mutated, ok := gen.mutate(s.Type, valid); ok {
+				c = mutated
+			}
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// AddCorpus adds the JSON encodings of n cases of s to corpus, such as the
+// seed corpus of a fuzz test:
+//
+//	func FuzzCreateOrder(f *testing.F) {
+//		s := jsonschema.Reflect(&Order{})
+//		if err := (&jsonschema.Fuzzer{}).AddCorpus(f, s, 20); err != nil {
+//			f.Fatal(err)
+//		}
+//		f.Fuzz(func(t *testing.T, body []byte) {
+//			valid := s.ValidateJSON(body) == nil
+//			...
+//		})
+//	}
+//
+// The fuzz target gets the encodings only, so it validates them to know
+// which are valid.
+func (f *Fuzzer) AddCorpus(corpus FuzzCorpus, s *Schema, n int) error {
+	cases, err := f.Generate(s, n)
+	if err != nil {
+		return err
+	}
+	for _, c := range cases {
+		data, err := json.Marshal(c.Instance)
+		if err != nil {
+			return err
+		}
+		corpus.Add(data)
+	}
+	return nil
+}
+
+// mutation is a change to an instance that violates keyword at path.
+type mutation struct {
+	keyword string
+	path    string
+	apply   func()
+}
+
+// fuzzProperty is the name of the properties added to closed objects.
+const fuzzProperty = "fuzzUnexpected"
+
+// mutate returns a mutation of the valid instance of t that violates one of
+// its keywords, and whether one was found.
+func (g *sampler) mutate(t *Type, valid interface{}) (FuzzCase, bool) {
+	for attempt := 0; attempt < sampleAttempts; attempt++ {
+		// Mutations apply to a copy of the instance, collected anew for each
+		// attempt.
+		instance := copyJSON(valid)
+		mutations := g.mutations(t, instance, "", func(value interface{}) { instance = value }, 0)
+		if len(mutations) == 0 {
+			return FuzzCase{}, false
+		}
+		m := mutations[g.rand.Intn(len(mutations))]
+		m.apply()
+		if errs, ok := g.resolver.Validate(t, instance).(ValidationErrors); ok {
+			for _, err := range errs {
+				if err.Keyword == m.keyword && err.InstancePath == m.path {
+					return FuzzCase{Instance: instance, Keyword: m.keyword, Path: m.path}, true
+				}
+			}
+		}
+	}
+	return FuzzCase{}, false
+}
+
+// mutations returns the mutations of value, at path within an instance, that
+// may violate t. set replaces value within the instance.
+func (g *sampler) mutations(t *Type, value interface{}, path string, set func(interface{}), depth int) []mutation {
+	if t == nil || depth > g.maxDepth+sampleRequiredDepth {
+		return nil
+	}
+	if _, ok := t.Boolean(); ok {
+		return nil
+	}
+	if t.Ref != "" {
+		target, err := g.resolver.Deref(t)
+		if err != nil {
+			return nil
+		}
+		return g.mutations(target, value, path, set, depth+1)
+	}
+
+	var mutations []mutation
+	add := func(keyword string, apply func()) {
+		mutations = append(mutations, mutation{keyword, path, apply})
+	}
+	setNumber := func(n float64) func() {
+		return func() { set(json.Number(formatNumber(n))) }
+	}
+
+	if types := t.typeList(); len(types) > 0 {
+		var others []interface{}
+		for _, other := range []interface{}{"fuzz", json.Number("1.5"), true, nil, []interface{}{}, map[string]interface{}{}} {
+			if !coversType(types, jsonType(other)) {
+				others = append(others, other)
+			}
+		}
+		if len(others) > 0 {
+			other := others[g.rand.Intn(len(others))]
+			add("type", func() { set(copyJSON(other)) })
+		}
+	}
+	if len(t.Enum) > 0 {
+		add("enum", func() { set("fuzz") })
+	}
+	if t.HasConst() {
+		add("const", func() { set("fuzz") })
+	}
+
+	switch v := value.(type) {
+	case string:
+		runes := []rune(v)
+		if t.MinLength > 0 && len(runes) >= t.MinLength {
+			add("minLength", func() { set(string(runes[:t.MinLength-1])) })
+		}
+		if t.MaxLength != nil {
+			add("maxLength", func() { set(v + strings.Repeat("x", *t.MaxLength+1-len(runes))) })
+		}
+	case []interface{}:
+		if t.MinItems > 0 && len(v) >= t.MinItems {
+			add("minItems", func() { set(v[:t.MinItems-1]) })
+		}
+		if t.MaxItems != nil && len(v) > 0 {
+			add("maxItems", func() {
+				items := v
+				for len(items) <= *t.MaxItems {
+					items = append(items, copyJSON(v[len(items)%len(v)]))
+				}
+				set(items)
+			})
+		}
+		if t.UniqueItems && len(v) > 0 {
+			add("uniqueItems", func() { set(append(v, copyJSON(v[0]))) })
+		}
+		for i := range v {
+			i := i
+			sub, _ := itemSchema(t, i, "")
+			mutations = append(mutations, g.mutations(sub, v[i], path+"/"+strconv.Itoa(i), func(item interface{}) { v[i] = item }, depth+1)...)
+		}
+	case map[string]interface{}:
+		for _, name := range t.Required {
+			if _, ok := v[name]; ok {
+				name := name
+				add("required", func() { delete(v, name) })
+			}
+		}
+		if t.closed() && g.propertySchema(t, fuzzProperty) == t.AdditionalProperties {
+			mutations = append(mutations, mutation{"additionalProperties", path + "/" + fuzzProperty, func() { v[fuzzProperty] = "fuzz" }})
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			name := name
+			mutations = append(mutations, g.mutations(g.propertySchema(t, name), v[name], path+"/"+escapePointerToken(name), func(item interface{}) { v[name] = item }, depth+1)...)
+		}
+	default:
+		if n, ok := toFloat(value); ok {
+			if min, exclusive, ok := t.minimum(); ok {
+				below := min - 1
+				if exclusive {
+					below = min
+				}
+				add(boundKeyword("minimum", t.ExclusiveMinimum), setNumber(below))
+			}
+			if max, exclusive, ok := t.maximum(); ok {
+				above := max + 1
+				if exclusive {
+					above = max
+				}
+				add(boundKeyword("maximum", t.ExclusiveMaximum), setNumber(above))
+			}
+			if m, err := t.MultipleOf.Float64(); err == nil && m > 0 {
+				add("multipleOf", setNumber(n+m/2))
+			}
+		}
+	}
+
+	for _, sub := range t.AllOf {
+		mutations = append(mutations, g.mutations(sub, value, path, set, depth+1)...)
+	}
+	return mutations
+}
@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonschema
+
+import (
+	"testing"
+)
+
+func FuzzOrder(f *testing.F) {
+	s := Reflect(&Order{})
+	if err := (&Fuzzer{Seed: 1}).AddCorpus(f, s, 20); err != nil {
+		f.Fatal(err)
+	}
+	v, err := Compile(s)
+	if err != nil {
+		f.Fatal(err)
+	}
+	// The interpreter and the compiled validator agree on every input.
+	f.Fuzz(func(t *testing.T, data []byte) {
+		interpreted := s.ValidateJSON(data)
+		compiled := v.ValidateJSON(data)
+		if (interpreted == nil) != (compiled == nil) {
+			t.Fatalf("interpreter: %v, compiled: %v", interpreted, compiled)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type corpusRecorder struct {
+	entries [][]interface{}
+}
+
+func (c *corpusRecorder) Add(args ...interface{}) {
+	c.entries = append(c.entries, args)
+}
+
+func TestFuzzer(t *testing.T) {
+	s := Reflect(&Order{})
+	f := &Fuzzer{Seed: 3}
+	cases, err := f.Generate(s, 200)
+	require.NoError(t, err)
+	require.Len(t, cases, 200)
+
+	keywords := map[string]bool{}
+	for i, c := range cases {
+		err := s.Validate(c.Instance)
+		if c.Valid() {
+			require.NoError(t, err, "case %d", i)
+			continue
+		}
+		keywords[c.Keyword] = true
+		require.Error(t, err, "case %d", i)
+		found := false
+		for _, failure := range err.(ValidationErrors) {
+			found = found || (failure.Keyword == c.Keyword && failure.InstancePath == c.Path)
+		}
+		require.True(t, found, "case %d violates %s at %q: %s", i, c.Keyword, c.Path, err)
+	}
+	for _, keyword := range []string{"required", "additionalProperties", "type", "enum", "minLength", "maxLength", "minimum", "maximum", "minItems"} {
+		require.True(t, keywords[keyword], "no case violates %s", keyword)
+	}
+
+	// The same seed yields the same cases.
+	again, err := (&Fuzzer{Seed: 3}).Generate(s, 200)
+	require.NoError(t, err)
+	require.Equal(t, cases, again)
+}
+
+func TestFuzzerSchemas(t *testing.T) {
+	s := mustSchema(t, `{
+		"type": "object",
+		"properties": {
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+			"ratio": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1},
+			"step": {"type": "integer", "multipleOf": 5},
+			"kind": {"const": "order"}
+		},
+		"required": ["tags", "ratio", "step", "kind"],
+		"additionalProperties": false
+	}`)
+	cases, err := (&Fuzzer{Seed: 1}).Generate(s, 200)
+	require.NoError(t, err)
+	keywords := map[string]bool{}
+	for _, c := range cases {
+		keywords[c.Keyword] = true
+	}
+	for _, keyword := range []string{"", "maxItems", "uniqueItems", "exclusiveMinimum", "exclusiveMaximum", "multipleOf", "const", "additionalProperties"} {
+		require.True(t, keywords[keyword], "no case violates %q", keyword)
+	}
+
+	// Schemas that nothing can violate yield valid cases only.
+	cases, err = (&Fuzzer{}).Generate(mustSchema(t, `{}`), 4)
+	require.NoError(t, err)
+	for _, c := range cases {
+		require.True(t, c.Valid())
+	}
+}
+
+func TestFuzzerAddCorpus(t *testing.T) {
+	s := Reflect(&Order{})
+	corpus := &corpusRecorder{}
+	require.NoError(t, (&Fuzzer{Seed: 5}).AddCorpus(corpus, s, 10))
+	require.Len(t, corpus.entries, 10)
+	for i, entry := range corpus.entries {
+		require.Len(t, entry, 1)
+		data := entry[0].([]byte)
+		require.True(t, json.Valid(data))
+		require.Equal(t, i%2 == 0, s.ValidateJSON(data) == nil, "entry %d: %s", i, data)
+	}
+}
@@ -1,5 +1,8 @@
 module github.com/alecthomas/jsonschema
 
-go 1.12
+go 1.16
 
-require github.com/stretchr/testify v1.3.1-0.20190311161405-34c6fa2dc709
+require (
+	github.com/stretchr/testify v1.3.1-0.20190311161405-34c6fa2dc709
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,471 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GoGenerator generates Go source code for the types described by a schema,
+// the reverse of a Reflector.
+//
+// Each definition becomes a named type, as does each object schema with
+// properties nested in another. Properties become struct fields in order, with
+// json tags that have omitempty for properties that are not required, and
+// jsonschema tags that carry the constraints of the property, so that
+// reflecting the generated types with the default Reflector yields an
+// equivalent schema. Optional properties of scalar and struct types are
+// pointers.
+//
+// Constraints that struct tags cannot express, such as those of array items,
+// or tag values containing commas, are left out. Null types and unions of
+// types become pointers and interface{}, which do not reflect back as such.
+// Properties whose names have no letters or digits become fields named after
+// their position, such as Field2.
+type GoGenerator struct {
+	// PackageName is the name of the package of the generated code. It
+	// defaults to "main".
+	PackageName string
+
+	// RootName is the name of the type generated for the root schema when it
+	// is not a reference to a definition. It defaults to the title of the
+	// schema, or "Root".
+	RootName string
+}
+
+// Generate returns gofmt'd Go source code for the types described by s.
+func (g *GoGenerator) Generate(s *Schema) ([]byte, error) {
+	gen := &goGenerator{
+		schema:      s,
+		definitions: map[string]string{},
+		names:       map[*Type]string{},
+		used:        map[string]bool{},
+		imports:     map[string]bool{},
+	}
+	definitions := mergedDefinitions(s)
+	for _, name := range sortedKeys(definitions) {
+		goName := goIdentifier(name)
+		if goName == "" {
+			goName = "Definition"
+		}
+		goName = gen.unique(goName)
+		gen.definitions[name] = goName
+		gen.names[definitions[name]] = goName
+	}
+	for _, name := range sortedKeys(definitions) {
+		gen.queue = append(gen.queue, definitions[name])
+	}
+	if s.Type != nil {
+		if _, ok := definitionRef(s.Type.Ref); !ok {
+			rootName := g.RootName
+			if rootName == "" {
+				rootName = goIdentifier(s.Type.Title)
+			}
+			if rootName == "" {
+				rootName = "Root"
+			}
+			gen.names[s.Type] = gen.unique(rootName)
+			gen.queue = append([]*Type{s.Type}, gen.queue...)
+		}
+	}
+	for len(gen.queue) > 0 {
+		t := gen.queue[0]
+		gen.queue = gen.queue[1:]
+		gen.declare(t)
+	}
+
+	out := &bytes.Buffer{}
+	packageName := g.PackageName
+	if packageName == "" {
+		packageName = "main"
+	}
+	fmt.Fprintf(out, "// Code generated by jsonschema. DO NOT EDIT.\n\npackage %s\n\n", packageName)
+	if len(gen.imports) > 0 {
+		imports := make([]string, 0, len(gen.imports))
+		for path := range gen.imports {
+			imports = append(imports, strconv.Quote(path))
+		}
+		sort.Strings(imports)
+		fmt.Fprintf(out, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+	out.Write(gen.out.Bytes())
+	return format.Source(out.Bytes())
+}
+
+type goGenerator struct {
+	schema      *Schema
+	definitions map[string]string // Go type names by definition name
+	names       map[*Type]string  // Go type names of named schemas
+	used        map[string]bool
+	imports     map[string]bool
+	queue       []*Type
+	out         bytes.Buffer
+}
+
+// unique returns name, or name with a number appended if it is already used.
+func (g *goGenerator) unique(name string) string {
+	candidate := name
+	for i := 2; g.used[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	g.used[candidate] = true
+	return candidate
+}
+
+// declare writes the declaration of the named type for t.
+func (g *goGenerator) declare(t *Type) {
+	name := g.names[t]
+	writeComment(&g.out, t.Description, "")
+	if !isStruct(t) {
+		fmt.Fprintf(&g.out, "type %s %s\n\n", name, g.goType(t, name, true))
+		return
+	}
+	fmt.Fprintf(&g.out, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	for i, property := range t.Properties.Keys() {
+		sub, _ := t.Properties.Get(property)
+		required := containsString(t.Required, property)
+		baseName := goIdentifier(property)
+		if baseName == "" {
+			baseName = "Field" + strconv.Itoa(i+1)
+		}
+		fieldName := baseName
+		for i := 2; fields[fieldName]; i++ {
+			fieldName = baseName + strconv.Itoa(i)
+		}
+		fields[fieldName] = true
+		g.field(fieldName, property, sub, required, name)
+	}
+	fmt.Fprintf(&g.out, "}\n\n")
+}
+
+// field writes a struct field for a property.
+func (g *goGenerator) field(name, property string, t *Type, required bool, parent string) {
+	// Constraints of definitions that are not structs are inlined by the
+	// Reflector, so carry them in the tags of the field.
+	constraints := t
+	if target := g.definition(t); target != nil && !isStruct(target) {
+		constraints = target.clone(nil)
+		constraints.annotate(t)
+	}
+
+	typ := g.goType(t, parent+name, required)
+	writeComment(&g.out, constraints.Description, "\t")
+
+	jsonTag := property
+	if property == "-" {
+		// A json tag of "-" alone ignores the field.
+		jsonTag += ","
+	}
+	if !required {
+		jsonTag += ",omitempty"
+	}
+	tags := []string{"json:" + strconv.Quote(jsonTag)}
+	if schemaTags := g.schemaTags(constraints, required); len(schemaTags) > 0 {
+		tags = append(tags, "jsonschema:"+strconv.Quote(strings.Join(schemaTags, ",")))
+	}
+	if constraints.Description != "" {
+		tags = append(tags, "jsonschema_description:"+strconv.Quote(constraints.Description))
+	}
+	tag := strings.Join(tags, " ")
+	if strings.Contains(tag, "`") {
+		tag = strconv.Quote(tag)
+	} else {
+		tag = "`" + tag + "`"
+	}
+	fmt.Fprintf(&g.out, "\t%s %s %s\n", name, typ, tag)
+}
+
+// schemaTags returns the jsonschema tag options that carry the constraints
+// of t, as read by the Reflector for the type of t.
+func (g *goGenerator) schemaTags(t *Type, required bool) []string {
+	var tags []string
+	add := func(name string, value interface{}) {
+		s := tagValue(value)
+		if !strings.Contains(s, ",") {
+			tags = append(tags, name+"="+s)
+		}
+	}
+	if required {
+		tags = append(tags, "required")
+	}
+	if t.Title != "" {
+		add("title", t.Title)
+	}
+
+	switch g.scalarType(t) {
+	case "string":
+		if t.MinLength > 0 {
+			add("minLength", t.MinLength)
+		}
+		if t.MaxLength != nil {
+			add("maxLength", *t.MaxLength)
+		}
+		if t.Pattern != "" {
+			add("pattern", t.Pattern)
+		}
+		if t.Format != "" && t.Format != "date-time" {
+			add("format", t.Format)
+		}
+	case "integer", "number":
+		if t.MultipleOf != "" {
+			add("multipleOf", t.MultipleOf)
+		}
+		if t.Minimum != "" {
+			add("minimum", t.Minimum)
+		}
+		if t.Maximum != "" {
+			add("maximum", t.Maximum)
+		}
+		if len(t.ExclusiveMinimum) > 0 {
+			add("exclusiveMinimum", string(t.ExclusiveMinimum))
+		}
+		if len(t.ExclusiveMaximum) > 0 {
+			add("exclusiveMaximum", string(t.ExclusiveMaximum))
+		}
+	case "array":
+		if t.MinItems > 0 {
+			add("minItems", t.MinItems)
+		}
+		if t.MaxItems != nil {
+			add("maxItems", *t.MaxItems)
+		}
+		if t.UniqueItems {
+			tags = append(tags, "uniqueItems=true")
+		}
+	default:
+		return tags
+	}
+
+	for _, value := range t.Enum {
+		add("enum", value)
+	}
+	if values, ok := t.Default.([]interface{}); ok {
+		for _, value := range values {
+			add("default", value)
+		}
+	} else if t.Default != nil {
+		add("default", t.Default)
+	}
+	for _, value := range t.Examples {
+		add("example", value)
+	}
+	return tags
+}
+
+// scalarType returns the JSON type of t that the Reflector reads tag
+// constraints for, if any.
+func (g *goGenerator) scalarType(t *Type) string {
+	if t.Ref != "" {
+		return ""
+	}
+	types := nonNullTypes(t)
+	if len(types) == 1 {
+		return types[0]
+	}
+	return ""
+}
+
+// goType returns the Go type for t. name is the name to give a nested struct
+// type, and required whether the value may be omitted.
+func (g *goGenerator) goType(t *Type, name string, required bool) string {
+	if ref, ok := definitionRef(t.Ref); ok {
+		target := g.definition(t)
+		goName, known := g.definitions[ref]
+		if target == nil || !known {
+			return "interface{}"
+		}
+		if isStruct(target) && !required {
+			return "*" + goName
+		}
+		if !required && isScalar(target) {
+			return "*" + goName
+		}
+		return goName
+	}
+	if g.names[t] != "" && name != g.names[t] {
+		if required {
+			return g.names[t]
+		}
+		return "*" + g.names[t]
+	}
+	if t.Ref != "" {
+		return "interface{}"
+	}
+
+	types := nonNullTypes(t)
+	nullable := len(types) < len(t.typeList())
+	pointer := func(typ string) string {
+		if !required || nullable {
+			return "*" + typ
+		}
+		return typ
+	}
+	if len(types) != 1 {
+		if isStruct(t) {
+			return pointer(g.nested(t, name))
+		}
+		return "interface{}"
+	}
+	switch types[0] {
+	case "string":
+		if t.Format == "date-time" {
+			g.imports["time"] = true
+			return pointer("time.Time")
+		}
+		if t.ContentEncoding == "base64" || (t.Media != nil && t.Media.BinaryEncoding == "base64") {
+			return "[]byte"
+		}
+		return pointer("string")
+	case "integer":
+		return pointer("int")
+	case "number":
+		return pointer("float64")
+	case "boolean":
+		return pointer("bool")
+	case "array":
+		if t.Items != nil {
+			return "[]" + g.goType(t.Items, name+"Item", true)
+		}
+		return "[]interface{}"
+	case "object":
+		if isStruct(t) {
+			return pointer(g.nested(t, name))
+		}
+		for _, sub := range t.PatternProperties {
+			if len(t.PatternProperties) == 1 {
+				return "map[string]" + g.goType(sub, name+"Value", true)
+			}
+		}
+		if t.AdditionalProperties != nil {
+			if _, ok := t.AdditionalProperties.Boolean(); !ok {
+				return "map[string]" + g.goType(t.AdditionalProperties, name+"Value", true)
+			}
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+// nested returns the name of the struct type for an object schema nested in
+// another, queuing its declaration.
+func (g *goGenerator) nested(t *Type, name string) string {
+	if goName, ok := g.names[t]; ok {
+		return goName
+	}
+	goName := g.unique(name)
+	g.names[t] = goName
+	g.queue = append(g.queue, t)
+	return goName
+}
+
+// definition returns the definition t refers to, if any.
+func (g *goGenerator) definition(t *Type) *Type {
+	name, ok := definitionRef(t.Ref)
+	if !ok {
+		return nil
+	}
+	return mergedDefinitions(g.schema)[name]
+}
+
+// mergedDefinitions returns the definitions of s and of its root type.
+func mergedDefinitions(s *Schema) Definitions {
+	definitions := Definitions{}
+	if s.Type != nil {
+		for name, def := range s.Type.Definitions {
+			definitions[name] = def
+		}
+	}
+	for name, def := range s.Definitions {
+		definitions[name] = def
+	}
+	return definitions
+}
+
+// isStruct reports whether t is an object schema with properties.
+func isStruct(t *Type) bool {
+	types := nonNullTypes(t)
+	return t.Properties.Len() > 0 && (len(types) == 0 || (len(types) == 1 && types[0] == "object"))
+}
+
+// isScalar reports whether t is a schema of a single scalar type.
+func isScalar(t *Type) bool {
+	types := nonNullTypes(t)
+	if len(types) != 1 {
+		return false
+	}
+	switch types[0] {
+	case "string", "integer", "number", "boolean":
+		return true
+	}
+	return false
+}
+
+// nonNullTypes returns the types of t other than null.
+func nonNullTypes(t *Type) []string {
+	var types []string
+	for _, typ := range t.typeList() {
+		if typ != "null" {
+			types = append(types, typ)
+		}
+	}
+	return types
+}
+
+// tagValue formats a JSON value for a struct tag.
+func tagValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	}
+	return canonicalJSON(value)
+}
+
+// goIdentifier converts a JSON name to an exported Go identifier, or returns
+// "" if the name has no letters or digits.
+func goIdentifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	b := &strings.Builder{}
+	for _, word := range words {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	id := b.String()
+	if id != "" && unicode.IsDigit([]rune(id)[0]) {
+		id = "X" + id
+	}
+	return id
+}
+
+var goInitialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true, "URI": true, "URL": true,
+	"UUID": true, "XML": true,
+}
+
+// writeComment writes text as a Go comment with the given indent.
+func writeComment(w *bytes.Buffer, text, indent string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(w, "%s// %s\n", indent, strings.TrimRightFunc(line, unicode.IsSpace))
+	}
+}
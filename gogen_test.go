@@ -0,0 +1,141 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoGenerator(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/gogen.json")
+	require.NoError(t, err)
+	s := &Schema{}
+	require.NoError(t, json.Unmarshal(data, s))
+
+	actual, err := (&GoGenerator{PackageName: "orders"}).Generate(s)
+	require.NoError(t, err)
+
+	expected, err := ioutil.ReadFile("fixtures/gogen.go.golden")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+}
+
+func TestGoIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"name":          "Name",
+		"user_id":       "UserID",
+		"api-url":       "APIURL",
+		"placedAt":      "PlacedAt",
+		"2fa":           "X2fa",
+		"x-custom.prop": "XCustomProp",
+		"-":             "",
+		"_":             "",
+	}
+	for name, expected := range tests {
+		require.Equal(t, expected, goIdentifier(name), name)
+	}
+}
+
+func TestGoGeneratorReflectsBack(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	data, err := ioutil.ReadFile("fixtures/gogen.json")
+	require.NoError(t, err)
+	s := &Schema{}
+	require.NoError(t, json.Unmarshal(data, s))
+	reflected := reflectGenerated(t, s, "Order")
+
+	// Null types and unions of types, which become pointers and interface{},
+	// are not reflected back, and Diff does not recognise the pattern
+	// property ".*" that maps are reflected with.
+	var breaking []string
+	for _, change := range Diff(s, reflected) {
+		if change.Breaking() {
+			breaking = append(breaking, change.Path)
+		}
+	}
+	require.Equal(t, []string{
+		"/properties/note/type",
+		"/properties/metadata/additionalProperties",
+		"/properties/discount/type",
+		"/properties/discount/oneOf/0",
+		"/properties/discount/oneOf/1",
+	}, breaking)
+
+	s = mustSchema(t, `{
+		"title": "names",
+		"type": "object",
+		"properties": {
+			"-": {"type": "string"},
+			"_": {"type": "integer"},
+			"__": {"type": "boolean"},
+			"2": {"type": "string", "maxLength": 0}
+		},
+		"required": ["-"]
+	}`)
+	reflected = reflectGenerated(t, s, "Names")
+	for _, change := range Diff(s, reflected) {
+		require.False(t, change.Breaking(), change.String())
+	}
+}
+
+// reflectGenerated generates the Go types of s, compiles them along with a
+// program that reflects the named root type, in a module that uses this one,
+// and returns the schema the program reflects, inlined as s is.
+func reflectGenerated(t *testing.T, s *Schema, root string) *Schema {
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command")
+	}
+	source, err := (&GoGenerator{}).Generate(s)
+	require.NoError(t, err)
+
+	module, err := filepath.Abs(".")
+	require.NoError(t, err)
+	sum, err := ioutil.ReadFile("go.sum")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module gogen\n\ngo 1.16\n\nrequire github.com/alecthomas/jsonschema v0.0.0\n\n" +
+			"replace github.com/alecthomas/jsonschema => " + module + "\n",
+		"go.sum":   string(sum),
+		"types.go": string(source),
+		"main.go": `package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/alecthomas/jsonschema"
+)
+
+func main() {
+	r := &jsonschema.Reflector{AllowAdditionalProperties: true}
+	_ = json.NewEncoder(os.Stdout).Encode(r.Reflect(&` + root + `{}))
+}
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	cmd := exec.Command(goCommand, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	output, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		t.Fatalf("go run: %s\n%s\n%s", err, exitErr.Stderr, source)
+	}
+	require.NoError(t, err)
+
+	reflected := &Schema{}
+	require.NoError(t, json.Unmarshal(output, reflected))
+	Inline(s)
+	Inline(reflected)
+	return reflected
+}
@@ -0,0 +1,455 @@
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidateValue validates the Go value v against the schema reflected from
+// its type, as Reflector.ValidateValue does with the default options.
+func ValidateValue(v interface{}) error {
+	return defaultReflector.ValidateValue(v)
+}
+
+// ValidateValue validates the Go value v against the schema reflected by r
+// from its type, without encoding it to JSON. The value is read in place, as
+// json.Marshal would encode it, naming struct fields as the Reflector does;
+// only the values of types that implement json.Marshaler are encoded, as
+// their encoding cannot be known otherwise. The error is ValidationErrors if
+// the value is not valid, in which GoPath gives the Go expression of each
+// failing value, such as "Order.Lines[0].SKU".
+//
+// The schema is compiled, and cached in the Cache of r if it has one.
+func (r *Reflector) ValidateValue(v interface{}) error {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return fmt.Errorf("jsonschema: cannot validate nil")
+	}
+	root := t
+	for root.Kind() == reflect.Ptr {
+		root = root.Elem()
+	}
+	validator, err := r.validator(root)
+	if err != nil {
+		return err
+	}
+	gr := &goReader{reflector: r, paths: map[string]string{}, layouts: map[reflect.Type]*goLayout{}}
+	err = validator.Validate(gr.value(reflect.ValueOf(v), "", root.Name()))
+	if gr.err != nil {
+		return gr.err
+	}
+	if errs, ok := err.(ValidationErrors); ok {
+		for _, e := range errs {
+			e.GoPath = gr.goPath(e.InstancePath)
+		}
+	}
+	return err
+}
+
+// goReader reads Go values as the JSON values they encode to, recording the
+// Go path of each. Arrays and objects are read in place, as they are
+// validated.
+type goReader struct {
+	reflector *Reflector
+	paths     map[string]string // Go paths by JSON Pointer
+	layouts   map[reflect.Type]*goLayout
+	err       error // the first value that could not be read
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// value returns the JSON value of v, found at pointer in the instance and at
+// goPath in the Go value. Arrays and objects are returned as an array or an
+// object that reads v.
+func (gr *goReader) value(v reflect.Value, pointer, goPath string) interface{} {
+	gr.paths[pointer] = goPath
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr && (v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType)) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if value, ok := gr.encoded(v, goPath); ok {
+		return value
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return json.Number(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes())
+		}
+		return &goArray{reader: gr, v: v, pointer: pointer, goPath: goPath}
+	case reflect.Array:
+		return &goArray{reader: gr, v: v, pointer: pointer, goPath: goPath}
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		return &goMap{reader: gr, v: v, pointer: pointer, goPath: goPath}
+	case reflect.Struct:
+		return &goStruct{reader: gr, v: v, layout: gr.layout(v.Type()), pointer: pointer, goPath: goPath}
+	}
+	gr.fail(goPath, fmt.Errorf("unsupported type %s", v.Type()))
+	return nil
+}
+
+// encoded returns the JSON value of v if it encodes itself, as json.Marshal
+// would, with the methods of its pointer type if it is addressable. Fields
+// promoted from unexported embedded structs cannot be, and are read.
+func (gr *goReader) encoded(v reflect.Value, goPath string) (interface{}, bool) {
+	if !v.CanInterface() {
+		return nil, false
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		if pt := reflect.PtrTo(v.Type()); pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType) {
+			v = v.Addr()
+		}
+	}
+	switch m := v.Interface().(type) {
+	case json.Marshaler:
+		data, err := m.MarshalJSON()
+		var value interface{}
+		if err == nil {
+			err = decodeJSON(data, &value)
+		}
+		if err != nil {
+			gr.fail(goPath, err)
+		}
+		return value, true
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		if err != nil {
+			gr.fail(goPath, err)
+		}
+		return string(text), true
+	}
+	return nil, false
+}
+
+// fail records that the value at goPath could not be read, if it is the
+// first.
+func (gr *goReader) fail(goPath string, err error) {
+	if gr.err == nil {
+		gr.err = fmt.Errorf("jsonschema: %s: %w", goPath, err)
+	}
+}
+
+// goPath returns the Go path of the value at pointer, or of its closest parent
+// with a known path.
+func (gr *goReader) goPath(pointer string) string {
+	for {
+		if path, ok := gr.paths[pointer]; ok {
+			return path
+		}
+		i := strings.LastIndexByte(pointer, '/')
+		if i < 0 {
+			return ""
+		}
+		pointer = pointer[:i]
+	}
+}
+
+// goArray is a Go slice or array read as a JSON array.
+type goArray struct {
+	reader          *goReader
+	v               reflect.Value
+	pointer, goPath string
+}
+
+func (a *goArray) len() int {
+	return a.v.Len()
+}
+
+func (a *goArray) index(i int) interface{} {
+	return a.reader.value(a.v.Index(i), a.pointer+"/"+strconv.Itoa(i), a.goPath+"["+strconv.Itoa(i)+"]")
+}
+
+// goMap is a Go map read as a JSON object. Its keys are named as json.Marshal
+// names them.
+type goMap struct {
+	reader          *goReader
+	v               reflect.Value
+	pointer, goPath string
+	keys            map[string]reflect.Value // by name, once names is called
+	sorted          []string
+}
+
+func (m *goMap) len() int {
+	return m.v.Len()
+}
+
+func (m *goMap) names() []string {
+	if m.keys == nil {
+		keys := m.v.MapKeys()
+		m.keys = make(map[string]reflect.Value, len(keys))
+		m.sorted = make([]string, 0, len(keys))
+		for _, key := range keys {
+			name, err := mapKeyName(key)
+			if err != nil {
+				m.reader.fail(m.goPath, err)
+				continue
+			}
+			m.keys[name] = key
+			m.sorted = append(m.sorted, name)
+		}
+		sort.Strings(m.sorted)
+	}
+	return m.sorted
+}
+
+func (m *goMap) property(name string) (interface{}, bool) {
+	var key reflect.Value
+	if keyType := m.v.Type().Key(); keyType.Kind() == reflect.String {
+		key = reflect.ValueOf(name).Convert(keyType)
+	} else {
+		m.names()
+		if key = m.keys[name]; !key.IsValid() {
+			return nil, false
+		}
+	}
+	value := m.v.MapIndex(key)
+	if !value.IsValid() {
+		return nil, false
+	}
+	return m.reader.value(value, m.pointer+"/"+escapePointerToken(name), m.goPath+"["+goMapKey(key)+"]"), true
+}
+
+// goStruct is a Go struct read as a JSON object, with the fields of its
+// layout.
+type goStruct struct {
+	reader          *goReader
+	v               reflect.Value
+	layout          *goLayout
+	pointer, goPath string
+	sorted          []string // once names is called
+}
+
+func (s *goStruct) len() int {
+	return len(s.names())
+}
+
+func (s *goStruct) names() []string {
+	if s.sorted != nil {
+		return s.sorted
+	}
+	s.sorted = []string{}
+	for name, i := range s.layout.byName {
+		if _, ok := s.field(s.layout.fields[i]); ok {
+			s.sorted = append(s.sorted, name)
+		}
+	}
+	for _, f := range s.layout.maps {
+		if m, ok := s.inlineMap(f); ok {
+			for _, name := range m.names() {
+				if _, ok := s.layout.byName[name]; !ok {
+					s.sorted = append(s.sorted, name)
+				}
+			}
+		}
+	}
+	sort.Strings(s.sorted)
+	return s.sorted
+}
+
+func (s *goStruct) property(name string) (interface{}, bool) {
+	if i, ok := s.layout.byName[name]; ok {
+		f := s.layout.fields[i]
+		field, ok := s.field(f)
+		if !ok {
+			return nil, false
+		}
+		return s.reader.value(field, s.pointer+"/"+escapePointerToken(name), s.goPath+f.selector), true
+	}
+	for _, f := range s.layout.maps {
+		if m, ok := s.inlineMap(f); ok {
+			if value, ok := m.property(name); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// field returns the value of the field f, unless it is omitted because it is
+// empty or within a nil embedded pointer.
+func (s *goStruct) field(f *goField) (reflect.Value, bool) {
+	v := s.v
+	for i, x := range f.index {
+		for i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	if f.omitEmpty && isEmptyValue(v) {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// inlineMap returns the map inlined at f, if it is not nil.
+func (s *goStruct) inlineMap(f *goField) (*goMap, bool) {
+	v, ok := s.field(f)
+	if !ok || v.IsNil() {
+		return nil, false
+	}
+	return &goMap{reader: s.reader, v: v, pointer: s.pointer, goPath: s.goPath + f.selector}, true
+}
+
+// goLayout is the layout of a struct type as an object: its fields named as
+// the Reflector names them, including those of the structs inlined into it,
+// and for PreferYAMLTags the maps tagged inline. A later field of the same
+// name takes precedence.
+type goLayout struct {
+	fields []*goField
+	byName map[string]int // indexes of fields
+	maps   []*goField
+}
+
+// goField is a field of a goLayout.
+type goField struct {
+	index     []int  // as for reflect.Value.FieldByIndex
+	selector  string // such as ".Audit.CreatedBy"
+	omitEmpty bool
+}
+
+// layout returns the layout of the struct type t.
+func (gr *goReader) layout(t reflect.Type) *goLayout {
+	if l, ok := gr.layouts[t]; ok {
+		return l
+	}
+	l := &goLayout{byName: map[string]int{}}
+	gr.layouts[t] = l
+	gr.addFields(l, t, nil, "", map[reflect.Type]bool{t: true})
+	return l
+}
+
+// addFields adds the fields of t, a struct found at index within the struct of
+// l, to l, skipping the structs in inlining that would inline themselves.
+func (gr *goReader) addFields(l *goLayout, t reflect.Type, index []int, selector string, inlining map[reflect.Type]bool) {
+	r := gr.reflector
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, exist, _ := r.reflectFieldName(f)
+		field := &goField{
+			index:     append(index[:len(index):len(index)], i),
+			selector:  selector + "." + f.Name,
+			omitEmpty: r.omitEmpty(f),
+		}
+		if name != "" {
+			l.byName[name] = len(l.fields)
+			l.fields = append(l.fields, field)
+			continue
+		}
+		if !r.inlined(f, exist) {
+			continue
+		}
+		if r.PreferYAMLTags && f.Type.Kind() == reflect.Map {
+			field.omitEmpty = false
+			l.maps = append(l.maps, field)
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || inlining[ft] {
+			continue
+		}
+		if f.Anonymous {
+			field.selector = selector
+		}
+		inlining[ft] = true
+		gr.addFields(l, ft, field.index, field.selector, inlining)
+		delete(inlining, ft)
+	}
+}
+
+// omitEmpty reports whether the field f has the omitempty option, in the tag
+// it is named from.
+func (r *Reflector) omitEmpty(f reflect.StructField) bool {
+	tag, _ := r.fieldTag(f)
+	for _, option := range strings.Split(tag, ",")[1:] {
+		if option == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue reports whether v is empty as json.Marshal defines it for
+// omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// mapKeyName returns the JSON object key of a map key, as json.Marshal
+// encodes it.
+func mapKeyName(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s", key.Type())
+}
+
+// goMapKey formats a map key as in a Go index expression.
+func goMapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return strconv.Quote(key.String())
+	}
+	return fmt.Sprint(key.Interface())
+}
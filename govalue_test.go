@@ -0,0 +1,118 @@
+package jsonschema
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Shipment struct {
+	ID        string            `json:"id" jsonschema:"pattern=^S[0-9]+$"`
+	Lines     []ShipmentLine    `json:"lines" jsonschema:"minItems=1"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	ShippedAt *time.Time        `json:"shipped_at,omitempty"`
+	Note      string            `yaml:"note,omitempty" jsonschema:"maxLength=5"`
+	ShipmentAudit
+}
+
+type ShipmentLine struct {
+	SKU      string `json:"sku" jsonschema:"minLength=3"`
+	Quantity int    `json:"quantity" jsonschema:"minimum=1"`
+}
+
+type ShipmentAudit struct {
+	CreatedBy string `json:"created_by" jsonschema:"format=email"`
+}
+
+func TestValidateValue(t *testing.T) {
+	shipped := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	valid := &Shipment{
+		ID:            "S1",
+		Lines:         []ShipmentLine{{SKU: "ABC", Quantity: 2}},
+		Labels:        map[string]string{"priority": "high"},
+		ShippedAt:     &shipped,
+		ShipmentAudit: ShipmentAudit{CreatedBy: "a@example.com"},
+	}
+	require.NoError(t, ValidateValue(valid))
+	require.NoError(t, ValidateValue(*valid))
+
+	err := ValidateValue(&Shipment{
+		ID:            "X1",
+		Lines:         []ShipmentLine{{SKU: "ABC", Quantity: 1}, {SKU: "A", Quantity: 0}},
+		Note:          "too long",
+		ShipmentAudit: ShipmentAudit{CreatedBy: "nobody"},
+	})
+	require.Equal(t, ValidationErrors{
+		{InstancePath: "/created_by", SchemaPath: "/$ref/properties/created_by/format", Keyword: "format", Message: `"nobody" is not a valid email`, GoPath: "Shipment.CreatedBy"},
+		{InstancePath: "/id", SchemaPath: "/$ref/properties/id/pattern", Keyword: "pattern", Message: `string does not match pattern "^S[0-9]+$"`, GoPath: "Shipment.ID"},
+		{InstancePath: "/lines/1/quantity", SchemaPath: "/$ref/properties/lines/items/$ref/properties/quantity/minimum", Keyword: "minimum", Message: "0 is less than 1", GoPath: "Shipment.Lines[1].Quantity"},
+		{InstancePath: "/lines/1/sku", SchemaPath: "/$ref/properties/lines/items/$ref/properties/sku/minLength", Keyword: "minLength", Message: "string is shorter than 3 characters", GoPath: "Shipment.Lines[1].SKU"},
+		{InstancePath: "/note", SchemaPath: "/$ref/properties/note/maxLength", Keyword: "maxLength", Message: "string is longer than 5 characters", GoPath: "Shipment.Note"},
+	}, err)
+
+	err = ValidateValue(&Shipment{ID: "S1", ShipmentAudit: ShipmentAudit{CreatedBy: "a@example.com"}})
+	require.EqualError(t, err, "/lines: expected array, but got null")
+	require.Equal(t, "Shipment.Lines", err.(ValidationErrors)[0].GoPath)
+
+	require.Error(t, ValidateValue(nil))
+}
+
+type Percent float64
+
+func (p *Percent) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(*p)*100, 'g', -1, 64)), nil
+}
+
+type Level int
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[l]), nil
+}
+
+type Discount struct {
+	Off    Percent                `json:"off" jsonschema:"maximum=100"`
+	Limits map[Level]ShipmentLine `json:"limits,omitempty"`
+	Codes  []string               `json:"codes,omitempty" jsonschema:"uniqueItems=true"`
+	Next   *Discount              `json:"next,omitempty"`
+}
+
+func TestValidateValueEncoders(t *testing.T) {
+	require.NoError(t, ValidateValue(&Discount{Off: 0.5, Limits: map[Level]ShipmentLine{1: {SKU: "ABC", Quantity: 1}}}))
+
+	err := ValidateValue(&Discount{
+		Off:    2,
+		Limits: map[Level]ShipmentLine{1: {SKU: "ABC"}},
+		Codes:  []string{"a", "a"},
+		Next:   &Discount{Off: 1.5},
+	})
+	require.Equal(t, ValidationErrors{
+		{InstancePath: "/codes", SchemaPath: "/$ref/properties/codes/uniqueItems", Keyword: "uniqueItems", Message: "items 0 and 1 are equal", GoPath: "Discount.Codes"},
+		{InstancePath: "/limits/high/quantity", SchemaPath: "/$ref/properties/limits/patternProperties/.*/$ref/properties/quantity/minimum", Keyword: "minimum", Message: "0 is less than 1", GoPath: "Discount.Limits[1].Quantity"},
+		{InstancePath: "/next/off", SchemaPath: "/$ref/properties/next/$ref/properties/off/maximum", Keyword: "maximum", Message: "150 is greater than 100", GoPath: "Discount.Next.Off"},
+		{InstancePath: "/off", SchemaPath: "/$ref/properties/off/maximum", Keyword: "maximum", Message: "200 is greater than 100", GoPath: "Discount.Off"},
+	}, err)
+}
+
+type YAMLSettings struct {
+	Name   string            `json:"name,omitempty" yaml:"name" jsonschema:"minLength=1"`
+	Extras map[string]string `yaml:",inline"`
+}
+
+func TestValidateValueYAMLTags(t *testing.T) {
+	// The name is not omitted, as its yaml tag has no omitempty.
+	r := &Reflector{PreferYAMLTags: true}
+	err := r.ValidateValue(&YAMLSettings{Extras: map[string]string{"debug": "on"}})
+	require.Equal(t, ValidationErrors{
+		{InstancePath: "/name", SchemaPath: "/$ref/properties/name/minLength", Keyword: "minLength", Message: "string is shorter than 1 characters", GoPath: "YAMLSettings.Name"},
+	}, err)
+}
+
+func TestValidateValueUnsupported(t *testing.T) {
+	type Callback struct {
+		Func interface{} `json:"func"`
+	}
+	err := (&Reflector{}).ValidateValue(&Callback{Func: func() {}})
+	require.EqualError(t, err, "jsonschema: Callback.Func: unsupported type func()")
+}
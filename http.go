@@ -0,0 +1,242 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Middleware validates the JSON request and response bodies of an
+// http.Handler against schemas, such as ones reflected from the Go types the
+// handler decodes and encodes.
+//
+// Invalid requests are rejected before they reach the handler with an RFC
+// 7807 problem+json response listing the validation errors. Validating
+// responses buffers them in full, and is meant for development and tests, to
+// catch handlers that drift from their declared types.
+//
+// The schemas are compiled once, by Handler, so they must not be modified
+// afterwards.
+type Middleware struct {
+	// Request is the schema of request bodies. Requests are not validated if
+	// it is nil. Requests without a body are passed through if their method
+	// does not expect one, such as GET.
+	Request *Schema
+
+	// Response is the schema of the bodies of successful (2xx) JSON
+	// responses, including non-empty ones without a Content-Type.
+	// Responses are not validated if it is nil. An invalid response is
+	// replaced with a 500 Internal Server Error problem response listing
+	// the validation errors.
+	Response *Schema
+
+	// MaxBodyBytes limits the size of request bodies, which are read in full
+	// to be validated. Larger requests are rejected with a 413 Request
+	// Entity Too Large problem response. If it is zero,
+	// DefaultMaxBodyBytes is used.
+	MaxBodyBytes int64
+}
+
+// DefaultMaxBodyBytes is the size limit of request bodies of a Middleware
+// without a MaxBodyBytes.
+const DefaultMaxBodyBytes = 1 << 20
+
+// errBodyTooLarge is the message of the error http.MaxBytesReader returns,
+// which has no type before Go 1.19.
+const errBodyTooLarge = "http: request body too large"
+
+// Problem is an RFC 7807 problem details object, as written by Middleware.
+type Problem struct {
+	Type   string           `json:"type,omitempty"`
+	Title  string           `json:"title"`
+	Status int              `json:"status"`
+	Detail string           `json:"detail,omitempty"`
+	Errors ValidationErrors `json:"errors,omitempty"`
+}
+
+// Handler returns a handler that validates the bodies of the requests to
+// next, and of its responses, against the schemas of m. It panics if a
+// schema cannot be compiled, such as one with an unresolvable reference.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	h := &validatingHandler{next: next, maxBodyBytes: m.MaxBodyBytes}
+	if h.maxBodyBytes <= 0 {
+		h.maxBodyBytes = DefaultMaxBodyBytes
+	}
+	var err error
+	if m.Request != nil {
+		if h.request, err = Compile(m.Request); err != nil {
+			panic("jsonschema: request schema: " + err.Error())
+		}
+	}
+	if m.Response != nil {
+		if h.response, err = Compile(m.Response); err != nil {
+			panic("jsonschema: response schema: " + err.Error())
+		}
+	}
+	return h
+}
+
+// validatingHandler is the handler returned by Middleware.Handler.
+type validatingHandler struct {
+	next         http.Handler
+	request      *Validator
+	response     *Validator
+	maxBodyBytes int64
+}
+
+func (h *validatingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.request != nil && !h.validateRequest(w, r) {
+		return
+	}
+	if h.response == nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	rec := &responseRecorder{header: http.Header{}}
+	h.next.ServeHTTP(rec, r)
+	h.writeResponse(w, rec)
+}
+
+// validateRequest validates the body of r, replacing it so that the handler
+// can read it, or writes a problem response and returns false.
+func (h *validatingHandler) validateRequest(w http.ResponseWriter, r *http.Request) bool {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+		r.Body.Close()
+		if err != nil && err.Error() == errBodyTooLarge {
+			writeProblem(w, &Problem{
+				Title:  "Request body is too large",
+				Status: http.StatusRequestEntityTooLarge,
+				Detail: "the limit is " + strconv.FormatInt(h.maxBodyBytes, 10) + " bytes",
+			})
+			return false
+		}
+		if err != nil {
+			writeProblem(w, &Problem{Title: "Request body could not be read", Status: http.StatusBadRequest, Detail: err.Error()})
+			return false
+		}
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+			return true
+		}
+		writeProblem(w, &Problem{Title: "Request body is empty", Status: http.StatusBadRequest})
+		return false
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "" && !isJSONMediaType(contentType) {
+		writeProblem(w, &Problem{
+			Title:  "Request body is not JSON",
+			Status: http.StatusUnsupportedMediaType,
+			Detail: "unsupported content type " + strconv.Quote(contentType),
+		})
+		return false
+	}
+
+	err := h.request.ValidateJSON(body)
+	var errs ValidationErrors
+	switch {
+	case errors.As(err, &errs):
+		writeProblem(w, &Problem{
+			Title:  "Request body does not match the schema",
+			Status: http.StatusUnprocessableEntity,
+			Errors: errs,
+		})
+		return false
+	case err != nil:
+		writeProblem(w, &Problem{Title: "Request body is not valid JSON", Status: http.StatusBadRequest, Detail: err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeResponse writes the recorded response to w, after validating its body
+// if it is a successful JSON response. A body without a Content-Type is taken
+// to be JSON, since sniffing would report JSON as text/plain.
+func (h *validatingHandler) writeResponse(w http.ResponseWriter, rec *responseRecorder) {
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	contentType := rec.header.Get("Content-Type")
+	if status >= 200 && status < 300 && isJSONResponse(contentType, rec.body.Len()) {
+		err := h.response.ValidateJSON(rec.body.Bytes())
+		if err != nil {
+			problem := &Problem{
+				Title:  "Response body does not match the schema",
+				Status: http.StatusInternalServerError,
+			}
+			if !errors.As(err, &problem.Errors) {
+				problem.Detail = err.Error()
+			}
+			writeProblem(w, problem)
+			return
+		}
+	}
+	for key, values := range rec.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(status)
+	w.Write(rec.body.Bytes())
+}
+
+// writeProblem writes an application/problem+json response.
+func writeProblem(w http.ResponseWriter, problem *Problem) {
+	body, _ := json.Marshal(problem)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(problem.Status)
+	w.Write(body)
+}
+
+// isJSONResponse reports whether a response body of the given content type
+// and length is JSON: either its type is JSON, or it has no type but a body.
+func isJSONResponse(contentType string, length int) bool {
+	if contentType == "" {
+		return length > 0
+	}
+	return isJSONMediaType(contentType)
+}
+
+// isJSONMediaType reports whether contentType is application/json or a
+// +json media type.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// responseRecorder buffers a response so that it can be validated before it
+// is written.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(data)
+}
@@ -0,0 +1,156 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type CreateWidget struct {
+	Name  string `json:"name" jsonschema:"minLength=1"`
+	Count int    `json:"count" jsonschema:"minimum=1"`
+}
+
+type Widget struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestMiddlewareRequest(t *testing.T) {
+	var received CreateWidget
+	handler := (&Middleware{Request: Reflect(&CreateWidget{})}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	tests := []struct {
+		method      string
+		contentType string
+		body        string
+		status      int
+		problem     string
+	}{
+		{"POST", "application/json", `{"name": "a", "count": 1}`, http.StatusCreated, ``},
+		{"GET", "", ``, http.StatusCreated, ``},
+		{"POST", "application/json", ``, http.StatusBadRequest, `{"title": "Request body is empty", "status": 400}`},
+		{"POST", "text/plain", `{}`, http.StatusUnsupportedMediaType, `{"title": "Request body is not JSON", "status": 415, "detail": "unsupported content type \"text/plain\""}`},
+		{"POST", "application/json", `{"name"`, http.StatusBadRequest, `{"title": "Request body is not valid JSON", "status": 400, "detail": "unexpected end of JSON input"}`},
+		{"PUT", "application/merge-patch+json", `{"name": "", "count": 1}`, http.StatusUnprocessableEntity, `{
+			"title": "Request body does not match the schema",
+			"status": 422,
+			"errors": [{"instancePath": "/name", "schemaPath": "/$ref/properties/name/minLength", "keyword": "minLength", "message": "string is shorter than 1 characters"}]
+		}`},
+	}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.body, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, "/widgets", strings.NewReader(test.body))
+			if test.contentType != "" {
+				r.Header.Set("Content-Type", test.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			require.Equal(t, test.status, w.Code)
+			if test.problem == "" {
+				return
+			}
+			require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			require.JSONEq(t, test.problem, w.Body.String())
+		})
+	}
+	require.Equal(t, CreateWidget{Name: "a", Count: 1}, received)
+}
+
+func TestMiddlewareResponse(t *testing.T) {
+	var response string
+	handler := (&Middleware{Response: Reflect(&Widget{})}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Widget", "1")
+		w.Write([]byte(response))
+	}))
+
+	response = `{"id": 1, "name": "a"}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/widgets/1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Header().Get("X-Widget"))
+	require.Equal(t, response, w.Body.String())
+
+	response = `{"id": "1", "name": "a"}`
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/widgets/1", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Empty(t, w.Header().Get("X-Widget"))
+	body, err := ioutil.ReadAll(w.Body)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"title": "Response body does not match the schema",
+		"status": 500,
+		"errors": [{"instancePath": "/id", "schemaPath": "/$ref/properties/id/type", "keyword": "type", "message": "expected integer, but got string"}]
+	}`, string(body))
+}
+
+func TestMiddlewareResponseWithoutContentType(t *testing.T) {
+	var response string
+	handler := (&Middleware{Response: Reflect(&Widget{})}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+
+	response = `{"id": 1, "name": "a"}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/widgets/1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, response, w.Body.String())
+
+	response = `{"id": "1", "name": "a"}`
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/widgets/1", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+	handler = (&Middleware{Response: Reflect(&Widget{})}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("DELETE", "/widgets/1", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestMiddlewareResponseNotValidated(t *testing.T) {
+	handler := (&Middleware{Response: Reflect(&Widget{})}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/widgets/2", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "not found\n", w.Body.String())
+}
+
+func TestMiddlewareMaxBodyBytes(t *testing.T) {
+	handler := (&Middleware{Request: Reflect(&CreateWidget{}), MaxBodyBytes: 32}).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/widgets", strings.NewReader(`{"name": "a", "count": 1}`)))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/widgets", strings.NewReader(`{"name": "`+strings.Repeat("a", 32)+`", "count": 1}`)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"title": "Request body is too large", "status": 413, "detail": "the limit is 32 bytes"}`, w.Body.String())
+}
+
+func TestMiddlewareInvalidSchema(t *testing.T) {
+	s := mustSchema(t, `{"$ref": "#/definitions/missing"}`)
+	require.Panics(t, func() {
+		(&Middleware{Request: s}).Handler(http.NotFoundHandler())
+	})
+}
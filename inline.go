@@ -0,0 +1,200 @@
+package jsonschema
+
+import (
+	"sort"
+	"strings"
+)
+
+// Inline replaces every $ref in s to one of its definitions, or to a
+// subschema within one such as "#/definitions/name/properties/id", with a
+// copy of the schema it refers to, and removes the definitions that are no
+// longer referenced.
+//
+// A reference is left in place where inlining it would never end, that is
+// within the schema it refers to. Annotations set alongside a reference,
+// such as a description reflected from a struct tag, are kept on the copy.
+// Other references, such as those to other documents, are left alone.
+func Inline(s *Schema) {
+	in := &inliner{schema: s, stack: map[string]bool{}}
+	if s.Type != nil {
+		version := s.Type.Version
+		s.Type = in.expand(s.Type)
+		if s.Type.Version == "" {
+			s.Type.Version = version
+		}
+	}
+
+	// Definitions that are still referenced are recursive. Their own
+	// references to other definitions are inlined too.
+	used := map[string]bool{}
+	var queue []string
+	collect := func(t *Type) {
+		for _, name := range definitionRefs(t) {
+			if !used[name] {
+				used[name] = true
+				queue = append(queue, name)
+			}
+		}
+	}
+	if s.Type != nil {
+		collect(s.Type)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		def := in.definition(name)
+		if def == nil {
+			continue
+		}
+		ref := "#/definitions/" + escapePointerToken(name)
+		in.stack[ref] = true
+		def.replaceSubschemas(func(tokens []string, sub *Type) *Type {
+			if tokens[0] == "definitions" {
+				return sub
+			}
+			return in.expand(sub)
+		})
+		delete(in.stack, ref)
+		collect(def)
+	}
+
+	for name := range s.Definitions {
+		if !used[name] {
+			delete(s.Definitions, name)
+		}
+	}
+	if s.Type != nil {
+		for name := range s.Type.Definitions {
+			if !used[name] {
+				delete(s.Type.Definitions, name)
+			}
+		}
+	}
+}
+
+type inliner struct {
+	schema *Schema
+	stack  map[string]bool // references being inlined
+}
+
+// expand returns t with its references to definitions inlined.
+func (in *inliner) expand(t *Type) *Type {
+	if name, tokens, ok := definitionPointer(t.Ref); ok && !in.stack[t.Ref] {
+		if target := in.pointer(name, tokens); target != nil {
+			in.stack[t.Ref] = true
+			c := in.expand(target.clone(nil))
+			delete(in.stack, t.Ref)
+			c.annotate(t)
+			return c
+		}
+	}
+	t.replaceSubschemas(func(tokens []string, sub *Type) *Type {
+		if tokens[0] == "definitions" {
+			return sub
+		}
+		return in.expand(sub)
+	})
+	return t
+}
+
+// definition returns the named definition of the schema.
+func (in *inliner) definition(name string) *Type {
+	if def, ok := in.schema.Definitions[name]; ok {
+		return def
+	}
+	if in.schema.Type != nil {
+		return in.schema.Type.Definitions[name]
+	}
+	return nil
+}
+
+// pointer returns the subschema at tokens within the named definition, or
+// nil if there is none.
+func (in *inliner) pointer(name string, tokens []string) *Type {
+	t := in.definition(name)
+	for t != nil && len(tokens) > 0 {
+		sub, n := t.subschema(tokens)
+		t, tokens = sub, tokens[n:]
+	}
+	return t
+}
+
+// annotate copies the annotations of ref, a reference to t, onto t.
+func (t *Type) annotate(ref *Type) {
+	if ref.Title != "" {
+		t.Title = ref.Title
+	}
+	if ref.Description != "" {
+		t.Description = ref.Description
+	}
+	if ref.Comment != "" {
+		t.Comment = ref.Comment
+	}
+	if ref.HasDefault() {
+		t.SetDefault(ref.Default)
+	}
+	if len(ref.Examples) > 0 {
+		t.Examples = ref.Examples
+	}
+	if ref.ReadOnly {
+		t.ReadOnly = true
+	}
+	if ref.WriteOnly {
+		t.WriteOnly = true
+	}
+	if ref.PropertyOrder != 0 {
+		t.PropertyOrder = ref.PropertyOrder
+	}
+	for key, value := range ref.Extras {
+		if t.Extras == nil {
+			t.Extras = map[string]interface{}{}
+		}
+		t.Extras[key] = value
+	}
+}
+
+// definitionRef returns the name of the definition ref points to, if it is a
+// local reference of the form "#/definitions/name".
+func definitionRef(ref string) (string, bool) {
+	name, tokens, ok := definitionPointer(ref)
+	return name, ok && len(tokens) == 0
+}
+
+// definitionPointer returns the name of the definition ref points into, and
+// the tokens of the pointer within the definition, if it is a local reference
+// of the form "#/definitions/name" or "#/definitions/name/properties/id".
+func definitionPointer(ref string) (string, []string, bool) {
+	if !strings.HasPrefix(ref, "#/definitions/") {
+		return "", nil, false
+	}
+	tokens := strings.Split(ref[len("#/definitions/"):], "/")
+	for i, token := range tokens {
+		tokens[i] = unescapePointerToken(token)
+	}
+	return tokens[0], tokens[1:], true
+}
+
+// definitionRefs returns the names of the definitions referenced within t,
+// other than from its own definitions, in order. A reference into a
+// definition is a reference to the definition.
+func definitionRefs(t *Type) []string {
+	seen := map[string]bool{}
+	var walk func(t *Type)
+	walk = func(t *Type) {
+		if name, _, ok := definitionPointer(t.Ref); ok {
+			seen[name] = true
+		}
+		t.eachSubschema(func(tokens []string, sub *Type) {
+			if tokens[0] != "definitions" {
+				walk(sub)
+			}
+		})
+	}
+	walk(t)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
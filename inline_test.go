@@ -0,0 +1,114 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type TreeNode struct {
+	Value    string      `json:"value"`
+	Children []*TreeNode `json:"children,omitempty"`
+	Owner    *TreeOwner  `json:"owner,omitempty" jsonschema_description:"who owns the node"`
+}
+
+type TreeOwner struct {
+	Name string `json:"name"`
+}
+
+func TestInlineRecursive(t *testing.T) {
+	s := (&Reflector{InlineRefs: true}).Reflect(&TreeNode{})
+	actual, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"type": "object",
+		"properties": {
+			"value": {"type": "string"},
+			"children": {
+				"type": "array",
+				"items": {"$ref": "#/definitions/TreeNode"}
+			},
+			"owner": {
+				"type": "object",
+				"properties": {"name": {"type": "string"}},
+				"required": ["name"],
+				"additionalProperties": false,
+				"description": "who owns the node"
+			}
+		},
+		"required": ["value"],
+		"additionalProperties": false,
+		"definitions": {
+			"TreeNode": {
+				"type": "object",
+				"properties": {
+					"value": {"type": "string"},
+					"children": {
+						"type": "array",
+						"items": {"$ref": "#/definitions/TreeNode"}
+					},
+					"owner": {
+						"type": "object",
+						"properties": {"name": {"type": "string"}},
+						"required": ["name"],
+						"additionalProperties": false,
+						"description": "who owns the node"
+					}
+				},
+				"required": ["value"],
+				"additionalProperties": false
+			}
+		}
+	}`, string(actual))
+}
+
+func TestInlineLeavesOtherRefs(t *testing.T) {
+	s := mustSchema(t, `{
+		"properties": {
+			"a": {"$ref": "#/definitions/A"},
+			"b": {"$ref": "other.json#/definitions/B"},
+			"c": {"$ref": "#/properties/a"}
+		},
+		"definitions": {
+			"A": {"type": "string"},
+			"Unused": {"type": "integer"}
+		}
+	}`)
+	Inline(s)
+	actual, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"properties": {
+			"a": {"type": "string"},
+			"b": {"$ref": "other.json#/definitions/B"},
+			"c": {"$ref": "#/properties/a"}
+		}
+	}`, string(actual))
+}
+
+func TestInlinePointerIntoDefinition(t *testing.T) {
+	s := mustSchema(t, `{
+		"properties": {
+			"id": {"$ref": "#/definitions/A/properties/id"},
+			"list": {"$ref": "#/definitions/List"}
+		},
+		"definitions": {
+			"A": {"properties": {"id": {"type": "string"}}},
+			"List": {"properties": {"next": {"$ref": "#/definitions/List/properties/next"}, "head": {"$ref": "#/definitions/A/properties/id"}}}
+		}
+	}`)
+	Inline(s)
+	actual, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"properties": {
+			"id": {"type": "string"},
+			"list": {"properties": {"next": {"$ref": "#/definitions/List/properties/next"}, "head": {"type": "string"}}}
+		},
+		"definitions": {
+			"List": {"properties": {"next": {"$ref": "#/definitions/List/properties/next"}, "head": {"type": "string"}}}
+		}
+	}`, string(actual))
+}
@@ -0,0 +1,223 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// keywords holds the JSON names of the keywords represented by fields of Type.
+var keywords = func() map[string]bool {
+	names := map[string]bool{"type": true, "items": true, "dependencies": true}
+	t := reflect.TypeOf(Type{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// MarshalJSON implements json.Marshaler.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	t := Type{}
+	if s.Type != nil {
+		t = *s.Type
+	}
+	if len(s.Definitions) > 0 {
+		definitions := Definitions{}
+		for name, def := range t.Definitions {
+			definitions[name] = def
+		}
+		for name, def := range s.Definitions {
+			definitions[name] = def
+		}
+		t.Definitions = definitions
+	}
+	return t.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The definitions of the root schema are moved to Schema.Definitions.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	t := &Type{}
+	if err := t.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	s.Type = t
+	s.Definitions = t.Definitions
+	t.Definitions = nil
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// Extras are marshaled after all other keywords, in key order.
+func (t Type) MarshalJSON() ([]byte, error) {
+	if t.boolean != nil {
+		return json.Marshal(*t.boolean)
+	}
+	extras := map[string]interface{}{}
+	for key, value := range t.Extras {
+		if !keywords[key] {
+			extras[key] = value
+		}
+	}
+	if len(t.Types) > 0 {
+		t.Type = ""
+		extras["type"] = t.Types
+	}
+	if t.TupleItems != nil {
+		t.Items = nil
+		extras["items"] = t.TupleItems
+	}
+	if len(t.DependentRequired) > 0 {
+		dependencies := map[string]interface{}{}
+		for name, dep := range t.Dependencies {
+			dependencies[name] = dep
+		}
+		for name, dep := range t.DependentRequired {
+			dependencies[name] = dep
+		}
+		t.Dependencies = nil
+		extras["dependencies"] = dependencies
+	}
+	if t.Const == nil && t.nullConst {
+		t.Const = json.RawMessage("null")
+	}
+	if t.Default == nil && t.nullDefault {
+		t.Default = json.RawMessage("null")
+	}
+
+	type plainType Type
+	data, err := json.Marshal((*plainType)(&t))
+	if err != nil || len(extras) == 0 {
+		return data, err
+	}
+	tail, err := json.Marshal(extras)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 2 {
+		return tail, nil
+	}
+	data[len(data)-1] = ','
+	return append(data, tail[1:]...), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// Numbers in Default, Const, Enum, Examples and Extras are decoded as
+// json.Number, so that they are marshaled back exactly as they were read. A
+// Default or Const of null is recorded as such; see HasDefault and HasConst.
+func (t *Type) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch string(data) {
+	case "true":
+		*t = *TrueSchema()
+		return nil
+	case "false":
+		*t = *FalseSchema()
+		return nil
+	}
+
+	type plainType Type
+	*t = Type{}
+	aux := struct {
+		*plainType
+		Type         json.RawMessage            `json:"type,omitempty"`
+		Items        json.RawMessage            `json:"items,omitempty"`
+		Dependencies map[string]json.RawMessage `json:"dependencies,omitempty"`
+	}{plainType: (*plainType)(t)}
+	if err := decodeJSON(data, &aux); err != nil {
+		return err
+	}
+
+	if isJSONArray(aux.Type) {
+		if err := json.Unmarshal(aux.Type, &t.Types); err != nil {
+			return err
+		}
+	} else if len(aux.Type) > 0 {
+		if err := json.Unmarshal(aux.Type, &t.Type); err != nil {
+			return err
+		}
+	}
+
+	if isJSONArray(aux.Items) {
+		if err := json.Unmarshal(aux.Items, &t.TupleItems); err != nil {
+			return err
+		}
+	} else if len(aux.Items) > 0 {
+		t.Items = &Type{}
+		if err := json.Unmarshal(aux.Items, t.Items); err != nil {
+			return err
+		}
+	}
+
+	for name, raw := range aux.Dependencies {
+		if isJSONArray(raw) {
+			var required []string
+			if err := json.Unmarshal(raw, &required); err != nil {
+				return err
+			}
+			if t.DependentRequired == nil {
+				t.DependentRequired = map[string][]string{}
+			}
+			t.DependentRequired[name] = required
+			continue
+		}
+		dep := &Type{}
+		if err := json.Unmarshal(raw, dep); err != nil {
+			return err
+		}
+		if t.Dependencies == nil {
+			t.Dependencies = map[string]*Type{}
+		}
+		t.Dependencies[name] = dep
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for key, raw := range all {
+		if keywords[key] {
+			switch key {
+			case "const":
+				t.nullConst = t.Const == nil
+			case "default":
+				t.nullDefault = t.Default == nil
+			}
+			continue
+		}
+		var value interface{}
+		if err := decodeJSON(raw, &value); err != nil {
+			return err
+		}
+		if t.Extras == nil {
+			t.Extras = map[string]interface{}{}
+		}
+		t.Extras[key] = value
+	}
+	return nil
+}
+
+// decodeJSON unmarshals data into v, decoding numbers as json.Number. Like
+// json.Unmarshal, it fails with a *json.SyntaxError if data is not a single
+// JSON value.
+func decodeJSON(data []byte, v interface{}) error {
+	if !json.Valid(data) {
+		var discard interface{}
+		return json.Unmarshal(data, &discard)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func isJSONArray(data json.RawMessage) bool {
+	return len(data) > 0 && data[0] == '['
+}
@@ -0,0 +1,103 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaRoundTrip(t *testing.T) {
+	fixtures := []string{
+		"fixtures/roundtrip_draft04.json",
+		"fixtures/roundtrip_draft07.json",
+	}
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		t.Run(name, func(t *testing.T) {
+			f, err := ioutil.ReadFile(fixture)
+			require.NoError(t, err)
+
+			schema := &Schema{}
+			err = json.Unmarshal(f, schema)
+			require.NoError(t, err)
+
+			actual, err := json.Marshal(schema)
+			require.NoError(t, err)
+			require.JSONEq(t, string(f), string(actual))
+		})
+	}
+}
+
+func TestSchemaRoundTripPresence(t *testing.T) {
+	inputs := []string{
+		`{"maxLength":0}`,
+		`{"maxItems":0,"maxProperties":0}`,
+		`{"const":null}`,
+		`{"default":null}`,
+		`{"properties":{"a":{"const":null,"default":null}}}`,
+	}
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			schema := &Schema{}
+			require.NoError(t, json.Unmarshal([]byte(input), schema))
+			actual, err := json.Marshal(schema)
+			require.NoError(t, err)
+			require.Equal(t, input, string(actual))
+		})
+	}
+
+	schema := &Type{}
+	require.NoError(t, json.Unmarshal([]byte(`{"const":null}`), schema))
+	require.True(t, schema.HasConst())
+	require.False(t, schema.HasDefault())
+	schema.DeleteConst()
+	schema.SetDefault(nil)
+	actual, err := json.Marshal(schema)
+	require.NoError(t, err)
+	require.Equal(t, `{"default":null}`, string(actual))
+}
+
+func TestSchemaUnmarshalForms(t *testing.T) {
+	schema := &Schema{}
+	f, err := ioutil.ReadFile("fixtures/roundtrip_draft04.json")
+	require.NoError(t, err)
+	err = json.Unmarshal(f, schema)
+	require.NoError(t, err)
+
+	require.Equal(t, "http://example.com/person.json", schema.Extras["id"])
+	require.Contains(t, schema.Definitions, "address")
+	require.Nil(t, schema.Type.Definitions)
+
+	age, _ := schema.Properties.Get("age")
+	require.Equal(t, []string{"integer", "null"}, age.Types)
+	require.Equal(t, json.Number("150.5"), age.Maximum)
+
+	tags, _ := schema.Properties.Get("tags")
+	require.Len(t, tags.TupleItems, 2)
+	value, ok := tags.AdditionalItems.Boolean()
+	require.True(t, ok)
+	require.False(t, value)
+
+	creditCard, _ := schema.Properties.Get("credit_card")
+	require.Equal(t, json.Number("12345678901234567890"), creditCard.Default)
+
+	require.Equal(t, []string{"billing_address"}, schema.DependentRequired["credit_card"])
+	require.Equal(t, []string{"age"}, schema.Dependencies["name"].Required)
+}
+
+func TestBooleanSchema(t *testing.T) {
+	data, err := json.Marshal(&Type{Items: TrueSchema(), AdditionalItems: FalseSchema()})
+	require.NoError(t, err)
+	require.Equal(t, `{"additionalItems":false,"items":true}`, string(data))
+
+	schema := &Type{}
+	err = json.Unmarshal([]byte("false"), schema)
+	require.NoError(t, err)
+	value, ok := schema.Boolean()
+	require.True(t, ok)
+	require.False(t, value)
+}
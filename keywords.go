@@ -0,0 +1,52 @@
+package jsonschema
+
+import (
+	"sort"
+	"sync"
+)
+
+// A KeywordValidator validates instance, a value decoded from JSON, against
+// value, the value of a custom keyword in a schema. The message of the error
+// it returns describes the failure.
+type KeywordValidator func(value, instance interface{}) error
+
+var (
+	customKeywords   = map[string]KeywordValidator{}
+	customKeywordsMu sync.RWMutex
+)
+
+// RegisterKeyword registers validate as the validator of the custom keyword
+// name, such as "x-unit", replacing any previous validator. Schemas that have
+// the keyword in their Extras are then validated by it, after their other
+// keywords. Validators compiled before the keyword is registered do not
+// validate it.
+func RegisterKeyword(name string, validate KeywordValidator) {
+	customKeywordsMu.Lock()
+	defer customKeywordsMu.Unlock()
+	customKeywords[name] = validate
+}
+
+// customKeyword is a custom keyword of a schema with a registered validator.
+type customKeyword struct {
+	name     string
+	value    interface{}
+	validate KeywordValidator
+}
+
+// registeredKeywords returns the keywords of t with a registered validator,
+// in name order.
+func (t *Type) registeredKeywords() []customKeyword {
+	if len(t.Extras) == 0 {
+		return nil
+	}
+	customKeywordsMu.RLock()
+	defer customKeywordsMu.RUnlock()
+	var keywords []customKeyword
+	for name, value := range t.Extras {
+		if validate, ok := customKeywords[name]; ok {
+			keywords = append(keywords, customKeyword{name, value, validate})
+		}
+	}
+	sort.Slice(keywords, func(i, j int) bool { return keywords[i].name < keywords[j].name })
+	return keywords
+}
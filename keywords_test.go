@@ -0,0 +1,83 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Timeouts struct {
+	Read     int    `json:"read" jsonschema_extras:"x-unit=ms,x-max-ms=5000"`
+	Password string `json:"password" jsonschema_extras:"x-sensitive=true"`
+	Labels   string `json:"labels" jsonschema_extras:"x-tags=a,x-tags=b,x-example={\"a\":[1,2]}"`
+}
+
+func TestExtrasTag(t *testing.T) {
+	s := (&Reflector{ExpandedStruct: true}).Reflect(&Timeouts{})
+	extras := func(name string) map[string]interface{} {
+		property, _ := s.Properties.Get(name)
+		return property.Extras
+	}
+	require.Equal(t, map[string]interface{}{"x-unit": "ms", "x-max-ms": json.Number("5000")}, extras("read"))
+	require.Equal(t, map[string]interface{}{"x-sensitive": true}, extras("password"))
+	require.Equal(t, map[string]interface{}{
+		"x-tags":    []interface{}{"a", "b"},
+		"x-example": map[string]interface{}{"a": []interface{}{json.Number("1"), json.Number("2")}},
+	}, extras("labels"))
+
+	data, err := json.Marshal(s.Properties)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"read":{"type":"integer","x-max-ms":5000,"x-unit":"ms"}`)
+}
+
+func TestExtrasTagReservedKeyword(t *testing.T) {
+	type Invalid struct {
+		Kind string `json:"kind" jsonschema_extras:"x-unit=ms,type=foo"`
+	}
+	require.PanicsWithValue(t, `jsonschema_extras of field Kind sets reserved keyword "type"`, func() {
+		(&Reflector{ExpandedStruct: true}).Reflect(&Invalid{})
+	})
+}
+
+func TestRegisterKeyword(t *testing.T) {
+	RegisterKeyword("x-max-ms", func(value, instance interface{}) error {
+		max, _ := toFloat(value)
+		if n, ok := toFloat(instance); ok && n > max {
+			return fmt.Errorf("%s ms is longer than %s ms", formatNumber(n), formatNumber(max))
+		}
+		return nil
+	})
+	RegisterKeyword("x-lowercase-keys", func(value, instance interface{}) error {
+		object, _ := instance.(map[string]interface{})
+		for key := range object {
+			if key != strings.ToLower(key) {
+				return fmt.Errorf("key %q is not lowercase", key)
+			}
+		}
+		return nil
+	})
+
+	s := (&Reflector{ExpandedStruct: true}).Reflect(&Timeouts{})
+	s.Extras = map[string]interface{}{"x-lowercase-keys": true}
+	s.AdditionalProperties = nil
+	valid := `{"read": 100, "password": "x", "labels": ""}`
+	invalid := `{"read": 6000, "password": "x", "labels": "", "Extra": 1}`
+	expected := ValidationErrors{
+		{InstancePath: "/read", SchemaPath: "/properties/read/x-max-ms", Keyword: "x-max-ms", Message: "6000 ms is longer than 5000 ms"},
+		{InstancePath: "", SchemaPath: "/x-lowercase-keys", Keyword: "x-lowercase-keys", Message: `key "Extra" is not lowercase`},
+	}
+
+	require.NoError(t, s.ValidateJSON([]byte(valid)))
+	require.Equal(t, expected, s.ValidateJSON([]byte(invalid)))
+
+	v, err := Compile(s)
+	require.NoError(t, err)
+	require.NoError(t, v.ValidateJSON([]byte(valid)))
+	require.Equal(t, expected, v.ValidateJSON([]byte(invalid)))
+
+	err = s.ValidateDecoder(json.NewDecoder(strings.NewReader(invalid)))
+	require.Equal(t, expected, err)
+}
@@ -0,0 +1,415 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Structural rewrites s into a Kubernetes structural schema, as required for
+// the openAPIV3Schema of a CustomResourceDefinition:
+//
+//   - References are inlined, as done by Inline, and the definitions removed.
+//     A recursive reference, which cannot be inlined, is replaced with a value
+//     whose unknown fields are preserved.
+//   - Every schema has a type, inferred from the values of enum or const if
+//     it has none, save for values of any type, which have
+//     x-kubernetes-preserve-unknown-fields instead, as do objects whose
+//     additionalProperties is true. Since Kubernetes prunes unknown fields,
+//     an additionalProperties of false is dropped.
+//   - A null type becomes nullable, and a type of either integer or string,
+//     including an anyOf or oneOf of them, becomes x-kubernetes-int-or-string.
+//   - The schema of patternProperties becomes that of additionalProperties.
+//   - Numeric exclusive bounds take the boolean form of draft-04, const
+//     becomes an enum, and the first of the examples becomes the example.
+//   - uniqueItems, which Kubernetes rejects, becomes an
+//     x-kubernetes-list-type of set for arrays of scalars.
+//   - Keywords that Kubernetes does not support, such as $schema, if or
+//     propertyNames, are dropped, along with the types and annotations of the
+//     subschemas of allOf, anyOf, oneOf and not.
+func Structural(s *Schema) {
+	Inline(s)
+	in := &inliner{schema: s, stack: map[string]bool{}}
+	if s.Type != nil {
+		s.Type = in.structural(s.Type, false)
+	}
+	s.Definitions = nil
+}
+
+// preserveUnknownFields returns the structural schema of values of type typ,
+// or of any type if typ is empty, whose unknown fields are preserved.
+func preserveUnknownFields(typ string) *Type {
+	return &Type{Type: typ, Extras: map[string]interface{}{"x-kubernetes-preserve-unknown-fields": true}}
+}
+
+// structural returns the structural schema of t. The schemas of allOf,
+// anyOf, oneOf and not are validations, which may only constrain values that
+// are specified by the schemas around them, and so have neither types nor
+// x-kubernetes-preserve-unknown-fields.
+func (in *inliner) structural(t *Type, validation bool) *Type {
+	if _, ok := t.Boolean(); ok {
+		if validation {
+			return &Type{}
+		}
+		return preserveUnknownFields("")
+	}
+	if t.Ref != "" {
+		var target *Type
+		if name, tokens, ok := definitionPointer(t.Ref); ok {
+			target = in.pointer(name, tokens)
+		}
+		if validation {
+			// The validations of the target are inlined as far as they do
+			// not recurse.
+			if target == nil || in.stack[t.Ref] {
+				return &Type{}
+			}
+			in.stack[t.Ref] = true
+			defer delete(in.stack, t.Ref)
+			return in.structural(target, true)
+		}
+		typ := ""
+		if target != nil {
+			typ = target.Type
+		}
+		st := preserveUnknownFields(typ)
+		st.Description = t.Description
+		return st
+	}
+
+	st := &Type{
+		Format:        t.Format,
+		Pattern:       t.Pattern,
+		MinLength:     t.MinLength,
+		MaxLength:     t.MaxLength,
+		MultipleOf:    t.MultipleOf,
+		MinItems:      t.MinItems,
+		MaxItems:      t.MaxItems,
+		MinProperties: t.MinProperties,
+		MaxProperties: t.MaxProperties,
+		Required:      t.Required,
+		Enum:          t.Enum,
+	}
+	st.Maximum, st.ExclusiveMaximum = structuralBound(t.Maximum, t.ExclusiveMaximum)
+	st.Minimum, st.ExclusiveMinimum = structuralBound(t.Minimum, t.ExclusiveMinimum)
+	if t.HasConst() {
+		st.Enum = []interface{}{t.Const}
+	}
+	if t.Media != nil && t.Media.BinaryEncoding == "base64" && st.Format == "" {
+		st.Format = "byte"
+	}
+	if t.Properties.Len() > 0 {
+		st.Properties = NewProperties()
+		for _, name := range t.Properties.Keys() {
+			sub, _ := t.Properties.Get(name)
+			st.Properties.Set(name, in.structural(sub, validation))
+		}
+	}
+	if t.Items != nil {
+		st.Items = in.structural(t.Items, validation)
+	}
+	for _, sub := range t.AllOf {
+		st.AllOf = append(st.AllOf, in.structural(sub, true))
+	}
+	if t.Not != nil {
+		st.Not = in.structural(t.Not, true)
+	}
+	intOrString := !validation && (isIntOrString(t.typeList()) ||
+		(len(t.typeList()) == 0 && (intOrStringBranches(t.AnyOf) || intOrStringBranches(t.OneOf))))
+	if !intOrString {
+		for _, sub := range t.AnyOf {
+			st.AnyOf = append(st.AnyOf, in.structural(sub, true))
+		}
+		for _, sub := range t.OneOf {
+			st.OneOf = append(st.OneOf, in.structural(sub, true))
+		}
+	}
+	if validation {
+		return st
+	}
+
+	st.Title = t.Title
+	st.Description = t.Description
+	if t.HasDefault() {
+		st.SetDefault(t.Default)
+	}
+	for key, value := range t.Extras {
+		if strings.HasPrefix(key, "x-kubernetes-") || key == "nullable" || key == "example" {
+			st.setExtra(key, value)
+		}
+	}
+	if len(t.Examples) > 0 {
+		st.setExtra("example", t.Examples[0])
+	}
+
+	var types []string
+	for _, typ := range t.typeList() {
+		if typ == "null" {
+			st.setExtra("nullable", true)
+		} else {
+			types = append(types, typ)
+		}
+	}
+	switch {
+	case intOrString:
+		st.setExtra("x-kubernetes-int-or-string", true)
+		st.AnyOf = []*Type{{Type: "integer"}, {Type: "string"}}
+	case len(types) == 1:
+		st.Type = types[0]
+	case len(types) == 0 && (t.Properties.Len() > 0 || t.AdditionalProperties != nil || len(t.PatternProperties) > 0):
+		st.Type = "object"
+	case len(types) == 0 && (t.Items != nil || t.TupleItems != nil):
+		st.Type = "array"
+	case len(types) == 0 && valuesType(st.Enum) != "":
+		st.Type = valuesType(st.Enum)
+		if st.Type == "object" {
+			st.setExtra("x-kubernetes-preserve-unknown-fields", true)
+		}
+	default:
+		st.setExtra("x-kubernetes-preserve-unknown-fields", true)
+	}
+
+	additional := t.AdditionalProperties
+	if additional == nil && len(t.PatternProperties) == 1 {
+		for _, sub := range t.PatternProperties {
+			additional = sub
+		}
+	} else if additional == nil && len(t.PatternProperties) > 1 {
+		additional = TrueSchema()
+	}
+	if additional != nil {
+		allowed, ok := additional.Boolean()
+		switch {
+		case ok && !allowed:
+			// Kubernetes prunes unknown fields anyway.
+		case ok || st.Properties.Len() > 0:
+			// Kubernetes rejects schemas with both properties and
+			// additionalProperties.
+			st.setExtra("x-kubernetes-preserve-unknown-fields", true)
+		default:
+			st.AdditionalProperties = in.structural(additional, false)
+		}
+	}
+
+	if st.Type == "array" && st.Items == nil {
+		st.Items = preserveUnknownFields("")
+	}
+	if t.UniqueItems && st.Items != nil && st.Extras["x-kubernetes-list-type"] == nil {
+		switch st.Items.Type {
+		case "string", "integer", "number", "boolean":
+			st.setExtra("x-kubernetes-list-type", "set")
+		}
+	}
+	return st
+}
+
+// structuralBound returns a bound and its exclusiveness in the boolean form
+// of draft-04.
+func structuralBound(value json.Number, exclusive json.RawMessage) (json.Number, json.RawMessage) {
+	switch s := strings.TrimSpace(string(exclusive)); s {
+	case "", "false":
+		return value, nil
+	case "true":
+		return value, json.RawMessage("true")
+	default:
+		return json.Number(s), json.RawMessage("true")
+	}
+}
+
+// valuesType returns the type of values, such as those of an enum, or "" if
+// they have different types or are null. Integers are numbers if some values
+// are not integers.
+func valuesType(values []interface{}) string {
+	typ := ""
+	for _, value := range values {
+		switch actual := jsonType(value); {
+		case actual == "null":
+			return ""
+		case typ == "" || typ == actual:
+			typ = actual
+		case (typ == "integer" || typ == "number") && (actual == "integer" || actual == "number"):
+			typ = "number"
+		default:
+			return ""
+		}
+	}
+	return typ
+}
+
+// isIntOrString reports whether types are integer and string.
+func isIntOrString(types []string) bool {
+	return len(types) == 2 && containsString(types, "integer") && containsString(types, "string")
+}
+
+// intOrStringBranches reports whether subs are the schemas of integers and
+// of strings, with no other keywords.
+func intOrStringBranches(subs []*Type) bool {
+	if len(subs) != 2 {
+		return false
+	}
+	var types []string
+	for _, sub := range subs {
+		if data, err := json.Marshal(sub); err != nil || string(data) != `{"type":"`+sub.Type+`"}` {
+			return false
+		}
+		types = append(types, sub.Type)
+	}
+	return isIntOrString(types)
+}
+
+// CRDGenerator generates the manifest of a Kubernetes CustomResourceDefinition
+// (apiextensions.k8s.io/v1) in YAML, with a version whose openAPIV3Schema is
+// the structural schema of a custom resource.
+//
+// The schema is typically reflected from the Go type of the resource, with
+// its apiVersion, kind, metadata, spec and status. It is made structural as
+// done by Structural, the metadata is reduced to an object, which Kubernetes
+// validates itself, and the apiVersion and kind are added if missing.
+type CRDGenerator struct {
+	// Group is the API group of the resource, such as "stable.example.com".
+	// It is required.
+	Group string
+
+	// Version is the name of the version of the resource. It defaults to
+	// "v1".
+	Version string
+
+	// Kind is the kind of the resource, such as "CronTab". It defaults to the
+	// name of the definition that the root of the schema refers to, such as
+	// the name of the reflected Go type.
+	Kind string
+
+	// Plural and Singular are the plural and singular names of the resource.
+	// They default to the lowercase kind, with an s for the plural.
+	Plural   string
+	Singular string
+
+	// ShortNames are the short names of the resource, such as "ct".
+	ShortNames []string
+
+	// Scope is either "Namespaced", the default, or "Cluster".
+	Scope string
+
+	// StatusSubresource enables the status subresource of the resource.
+	StatusSubresource bool
+}
+
+type crdManifest struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind       string   `json:"kind"`
+			ListKind   string   `json:"listKind"`
+			Plural     string   `json:"plural"`
+			ShortNames []string `json:"shortNames,omitempty"`
+			Singular   string   `json:"singular"`
+		} `json:"names"`
+		Scope    string       `json:"scope"`
+		Versions []crdVersion `json:"versions"`
+	} `json:"spec"`
+}
+
+type crdVersion struct {
+	Name   string `json:"name"`
+	Schema struct {
+		OpenAPIV3Schema *Type `json:"openAPIV3Schema"`
+	} `json:"schema"`
+	Served       bool `json:"served"`
+	Storage      bool `json:"storage"`
+	Subresources *struct {
+		Status struct{} `json:"status"`
+	} `json:"subresources,omitempty"`
+}
+
+// Generate generates the CustomResourceDefinition of the resource whose schema
+// is s. The schema itself is left unchanged.
+func (g *CRDGenerator) Generate(s *Schema) ([]byte, error) {
+	if g.Group == "" {
+		return nil, errors.New("jsonschema: the group of a CustomResourceDefinition is required")
+	}
+	kind := g.Kind
+	if kind == "" {
+		kind, _ = definitionRef(s.Ref)
+	}
+	if kind == "" {
+		return nil, errors.New("jsonschema: the kind of a CustomResourceDefinition is required")
+	}
+
+	c := &Schema{Type: s.Type.clone(nil), Definitions: Definitions{}}
+	for name, def := range s.Definitions {
+		c.Definitions[name] = def.clone(nil)
+	}
+	Structural(c)
+	root := c.Type
+	root.Type = "object"
+	delete(root.Extras, "x-kubernetes-preserve-unknown-fields")
+	properties := NewProperties()
+	for _, name := range []string{"apiVersion", "kind"} {
+		sub, ok := root.Properties.Get(name)
+		if !ok {
+			sub = &Type{Type: "string"}
+		}
+		properties.Set(name, sub)
+	}
+	properties.Set("metadata", &Type{Type: "object"})
+	for _, name := range root.Properties.Keys() {
+		if _, ok := properties.Get(name); !ok {
+			sub, _ := root.Properties.Get(name)
+			properties.Set(name, sub)
+		}
+	}
+	root.Properties = properties
+	var required []string
+	for _, name := range root.Required {
+		if name != "apiVersion" && name != "kind" && name != "metadata" {
+			required = append(required, name)
+		}
+	}
+	root.Required = required
+
+	m := &crdManifest{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"}
+	names := &m.Spec.Names
+	names.Kind = kind
+	names.ListKind = kind + "List"
+	names.Plural = g.Plural
+	if names.Plural == "" {
+		names.Plural = strings.ToLower(kind) + "s"
+	}
+	names.Singular = g.Singular
+	if names.Singular == "" {
+		names.Singular = strings.ToLower(kind)
+	}
+	names.ShortNames = g.ShortNames
+	m.Metadata.Name = names.Plural + "." + g.Group
+	m.Spec.Group = g.Group
+	m.Spec.Scope = g.Scope
+	if m.Spec.Scope == "" {
+		m.Spec.Scope = "Namespaced"
+	}
+	version := crdVersion{Name: g.Version, Served: true, Storage: true}
+	if version.Name == "" {
+		version.Name = "v1"
+	}
+	version.Schema.OpenAPIV3Schema = root
+	if g.StatusSubresource {
+		version.Subresources = &struct {
+			Status struct{} `json:"status"`
+		}{}
+	}
+	m.Spec.Versions = []crdVersion{version}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	node, err := jsonToYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeYAML(node)
+}
@@ -0,0 +1,146 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type CronTab struct {
+	APIVersion string         `json:"apiVersion,omitempty"`
+	Kind       string         `json:"kind,omitempty"`
+	Metadata   ObjectMeta     `json:"metadata,omitempty"`
+	Spec       CronTabSpec    `json:"spec"`
+	Status     *CronTabStatus `json:"status,omitempty"`
+}
+
+type ObjectMeta struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type CronTabSpec struct {
+	CronSpec string            `json:"cronSpec" jsonschema:"pattern=^(\\d+|\\*)(/\\d+)?(\\s+(\\d+|\\*)(/\\d+)?){4}$"`
+	Image    string            `json:"image" jsonschema:"description=image to run"`
+	Replicas int               `json:"replicas,omitempty" jsonschema:"minimum=1,exclusiveMaximum=10,default=1"`
+	MaxSkew  Quantity          `json:"maxSkew,omitempty"`
+	Priority ProtoEnum         `json:"priority,omitempty"`
+	Env      []EnvVar          `json:"env,omitempty" jsonschema:"listType=map,listMapKey=name"`
+	Args     []string          `json:"args,omitempty" jsonschema:"uniqueItems=true"`
+	Selector map[string]string `json:"selector,omitempty"`
+	Config   interface{}       `json:"config,omitempty"`
+	Template PodTemplate       `json:"template,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Fallback *CronTabSpec      `json:"fallback,omitempty"`
+}
+
+type Quantity struct {
+	value string
+}
+
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type PodTemplate struct {
+	Containers []string `json:"containers"`
+}
+
+type CronTabStatus struct {
+	LastScheduleTime string `json:"lastScheduleTime,omitempty" jsonschema:"format=date-time"`
+}
+
+func TestCRDGenerator(t *testing.T) {
+	r := &Reflector{
+		Structural:       true,
+		IgnoredTypes:     []interface{}{PodTemplate{}},
+		IntOrStringTypes: []interface{}{Quantity{}},
+	}
+	s := r.Reflect(&CronTab{})
+	require.Empty(t, s.Definitions)
+	require.Empty(t, definitionRefs(s.Type))
+
+	g := &CRDGenerator{
+		Group:             "stable.example.com",
+		Kind:              "CronTab",
+		ShortNames:        []string{"ct"},
+		StatusSubresource: true,
+	}
+	actual, err := g.Generate(s)
+	require.NoError(t, err)
+	expected, err := ioutil.ReadFile("fixtures/crontab.crd.yaml.golden")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+
+	// The kind defaults to the name of the reflected type, and the schema is
+	// made structural without changing it.
+	s = Reflect(&CronTab{})
+	before, err := json.Marshal(s)
+	require.NoError(t, err)
+	actual, err = (&CRDGenerator{Group: "stable.example.com"}).Generate(s)
+	require.NoError(t, err)
+	require.Contains(t, string(actual), "name: crontabs.stable.example.com\n")
+	require.Contains(t, string(actual), "    kind: CronTab\n")
+	after, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, string(before), string(after))
+
+	_, err = (&CRDGenerator{}).Generate(s)
+	require.EqualError(t, err, "jsonschema: the group of a CustomResourceDefinition is required")
+	_, err = (&CRDGenerator{Group: "stable.example.com"}).Generate(mustSchema(t, `{"type": "object"}`))
+	require.EqualError(t, err, "jsonschema: the kind of a CustomResourceDefinition is required")
+}
+
+func TestStructural(t *testing.T) {
+	s := mustSchema(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"definitions": {
+			"name": {"type": "string", "minLength": 1},
+			"node": {"type": "object", "required": ["value"], "properties": {"value": {"type": "string"}, "next": {"$ref": "#/definitions/node"}}}
+		},
+		"type": "object",
+		"properties": {
+			"name": {"$ref": "#/definitions/name", "description": "the name"},
+			"nickname": {"type": ["string", "null"], "examples": ["bob"]},
+			"port": {"type": ["integer", "string"]},
+			"ratio": {"type": "number", "exclusiveMinimum": 0, "maximum": 1},
+			"mode": {"const": "fast"},
+			"level": {"enum": [1, 2.5]},
+			"node": {"type": "object", "allOf": [true, {"$ref": "#/definitions/node"}]},
+			"labels": {"type": "object", "patternProperties": {"^x-": {"type": "string"}}},
+			"extra": {"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": {"type": "integer"}},
+			"tuple": {"type": "array", "items": [{"type": "string"}], "contains": {"type": "string"}},
+			"choice": {"type": "object", "oneOf": [{"required": ["a"]}, {"required": ["b"], "description": "b"}]},
+			"any": {}
+		},
+		"propertyNames": {"maxLength": 10},
+		"if": {"required": ["a"]},
+		"then": {"required": ["b"]}
+	}`)
+	Structural(s)
+	actual, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "description": "the name"},
+			"nickname": {"type": "string", "nullable": true, "example": "bob"},
+			"port": {"anyOf": [{"type": "integer"}, {"type": "string"}], "x-kubernetes-int-or-string": true},
+			"ratio": {"type": "number", "minimum": 0, "exclusiveMinimum": true, "maximum": 1},
+			"mode": {"type": "string", "enum": ["fast"]},
+			"level": {"type": "number", "enum": [1, 2.5]},
+			"node": {"type": "object", "allOf": [{}, {
+				"required": ["value"],
+				"properties": {"value": {}, "next": {"required": ["value"], "properties": {"value": {}, "next": {}}}}
+			}]},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"extra": {"type": "object", "properties": {"a": {"type": "string"}}, "x-kubernetes-preserve-unknown-fields": true},
+			"tuple": {"type": "array", "items": {"x-kubernetes-preserve-unknown-fields": true}},
+			"choice": {"type": "object", "oneOf": [{"required": ["a"]}, {"required": ["b"]}]},
+			"any": {"x-kubernetes-preserve-unknown-fields": true}
+		}
+	}`, string(actual))
+}
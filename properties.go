@@ -0,0 +1,133 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Properties is an ordered set of property schemas, keyed by property name.
+//
+// Properties marshal to a JSON object whose keys appear in insertion order,
+// and unmarshal preserving the order of the keys in the source document. When
+// reflecting, properties are inserted in struct field order, with the fields
+// of embedded structs appearing in place of the embedded field.
+type Properties struct {
+	names  []string
+	values map[string]*Type
+}
+
+// NewProperties creates an empty set of properties.
+func NewProperties() *Properties {
+	return &Properties{values: map[string]*Type{}}
+}
+
+// Len returns the number of properties.
+func (p *Properties) Len() int {
+	if p == nil {
+		return 0
+	}
+	return len(p.names)
+}
+
+// Keys returns the property names in order.
+func (p *Properties) Keys() []string {
+	if p == nil {
+		return nil
+	}
+	return append([]string(nil), p.names...)
+}
+
+// Get returns the schema of the named property.
+func (p *Properties) Get(name string) (*Type, bool) {
+	if p == nil {
+		return nil, false
+	}
+	t, ok := p.values[name]
+	return t, ok
+}
+
+// Set adds or replaces a property. A replaced property keeps its position.
+func (p *Properties) Set(name string, t *Type) {
+	if p.values == nil {
+		p.values = map[string]*Type{}
+	}
+	if _, ok := p.values[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.values[name] = t
+}
+
+// Delete removes a property.
+func (p *Properties) Delete(name string) {
+	if p == nil {
+		return
+	}
+	if _, ok := p.values[name]; !ok {
+		return
+	}
+	i := p.index(name)
+	p.names = append(p.names[:i], p.names[i+1:]...)
+	delete(p.values, name)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p *Properties) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, name := range p.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(p.values[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("jsonschema: properties must be an object, not %v", tok)
+	}
+	*p = Properties{values: map[string]*Type{}}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name := tok.(string)
+		t := &Type{}
+		if err := dec.Decode(t); err != nil {
+			return err
+		}
+		p.Set(name, t)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// index returns the position of the named property, or -1.
+func (p *Properties) index(name string) int {
+	for i, n := range p.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,45 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertiesRoundTripOrder(t *testing.T) {
+	input := `{"zeta":{"type":"string"},"alpha":{"type":"integer"},"mid":{"type":"boolean"}}`
+	props := &Properties{}
+	err := json.Unmarshal([]byte(input), props)
+	require.NoError(t, err)
+	require.Equal(t, []string{"zeta", "alpha", "mid"}, props.Keys())
+
+	output, err := json.Marshal(props)
+	require.NoError(t, err)
+	require.Equal(t, input, string(output))
+}
+
+func TestPropertiesSetDelete(t *testing.T) {
+	props := NewProperties()
+	props.Set("a", &Type{Type: "string"})
+	props.Set("b", &Type{Type: "integer"})
+	props.Set("a", &Type{Type: "boolean"})
+	require.Equal(t, []string{"a", "b"}, props.Keys())
+	a, ok := props.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "boolean", a.Type)
+
+	props.Delete("a")
+	props.Delete("missing")
+	require.Equal(t, []string{"b"}, props.Keys())
+	require.Equal(t, 1, props.Len())
+}
+
+func TestPropertiesNil(t *testing.T) {
+	var props *Properties
+	props.Delete("a")
+	_, ok := props.Get("a")
+	require.False(t, ok)
+	require.Equal(t, 0, props.Len())
+	require.Empty(t, props.Keys())
+}
@@ -29,47 +29,131 @@ type Schema struct {
 }
 
 // Type represents a JSON Schema object type.
+//
+// Keywords that may take more than one form in a JSON Schema document are
+// represented by a pair of fields, one for each form: Type and Types, Items
+// and TupleItems, Dependencies and DependentRequired. Keywords not otherwise
+// represented by a field are kept in Extras.
 type Type struct {
 	// RFC draft-wright-json-schema-00
-	Version string `json:"$schema,omitempty"` // section 6.1
-	Ref     string `json:"$ref,omitempty"`    // section 7
+	Version string `json:"$schema,omitempty"`  // section 6.1
+	ID      string `json:"$id,omitempty"`      // draft-wright-json-schema-01, section 9.2
+	Ref     string `json:"$ref,omitempty"`     // section 7
+	Comment string `json:"$comment,omitempty"` // draft-handrews-json-schema-00, section 9
 	// RFC draft-wright-json-schema-validation-00, section 5
-	MultipleOf           int              `json:"multipleOf,omitempty"`           // section 5.1
-	Maximum              int              `json:"maximum,omitempty"`              // section 5.2
-	ExclusiveMaximum     bool             `json:"exclusiveMaximum,omitempty"`     // section 5.3
-	Minimum              int              `json:"minimum,omitempty"`              // section 5.4
-	ExclusiveMinimum     bool             `json:"exclusiveMinimum,omitempty"`     // section 5.5
-	MaxLength            int              `json:"maxLength,omitempty"`            // section 5.6
-	MinLength            int              `json:"minLength,omitempty"`            // section 5.7
-	Pattern              string           `json:"pattern,omitempty"`              // section 5.8
-	AdditionalItems      *Type            `json:"additionalItems,omitempty"`      // section 5.9
-	Items                *Type            `json:"items,omitempty"`                // section 5.9
-	MaxItems             int              `json:"maxItems,omitempty"`             // section 5.10
-	MinItems             int              `json:"minItems,omitempty"`             // section 5.11
-	UniqueItems          bool             `json:"uniqueItems,omitempty"`          // section 5.12
-	MaxProperties        int              `json:"maxProperties,omitempty"`        // section 5.13
-	MinProperties        int              `json:"minProperties,omitempty"`        // section 5.14
-	Required             []string         `json:"required,omitempty"`             // section 5.15
-	Properties           map[string]*Type `json:"properties,omitempty"`           // section 5.16
-	PatternProperties    map[string]*Type `json:"patternProperties,omitempty"`    // section 5.17
-	AdditionalProperties json.RawMessage  `json:"additionalProperties,omitempty"` // section 5.18
-	Dependencies         map[string]*Type `json:"dependencies,omitempty"`         // section 5.19
-	Enum                 []interface{}    `json:"enum,omitempty"`                 // section 5.20
-	Type                 string           `json:"type,omitempty"`                 // section 5.21
-	AllOf                []*Type          `json:"allOf,omitempty"`                // section 5.22
-	AnyOf                []*Type          `json:"anyOf,omitempty"`                // section 5.23
-	OneOf                []*Type          `json:"oneOf,omitempty"`                // section 5.24
-	Not                  *Type            `json:"not,omitempty"`                  // section 5.25
-	Definitions          Definitions      `json:"definitions,omitempty"`          // section 5.26
+	MultipleOf           json.Number         `json:"multipleOf,omitempty"`           // section 5.1
+	Maximum              json.Number         `json:"maximum,omitempty"`              // section 5.2
+	ExclusiveMaximum     json.RawMessage     `json:"exclusiveMaximum,omitempty"`     // section 5.3
+	Minimum              json.Number         `json:"minimum,omitempty"`              // section 5.4
+	ExclusiveMinimum     json.RawMessage     `json:"exclusiveMinimum,omitempty"`     // section 5.5
+	MaxLength            *int                `json:"maxLength,omitempty"`            // section 5.6
+	MinLength            int                 `json:"minLength,omitempty"`            // section 5.7
+	Pattern              string              `json:"pattern,omitempty"`              // section 5.8
+	AdditionalItems      *Type               `json:"additionalItems,omitempty"`      // section 5.9
+	Items                *Type               `json:"items,omitempty"`                // section 5.9
+	TupleItems           []*Type             `json:"-"`                              // section 5.9
+	MaxItems             *int                `json:"maxItems,omitempty"`             // section 5.10
+	MinItems             int                 `json:"minItems,omitempty"`             // section 5.11
+	UniqueItems          bool                `json:"uniqueItems,omitempty"`          // section 5.12
+	Contains             *Type               `json:"contains,omitempty"`             // draft-wright-json-schema-validation-01, section 6.14
+	MaxProperties        *int                `json:"maxProperties,omitempty"`        // section 5.13
+	MinProperties        int                 `json:"minProperties,omitempty"`        // section 5.14
+	Required             []string            `json:"required,omitempty"`             // section 5.15
+	Properties           *Properties         `json:"properties,omitempty"`           // section 5.16
+	PatternProperties    map[string]*Type    `json:"patternProperties,omitempty"`    // section 5.17
+	AdditionalProperties *Type               `json:"additionalProperties,omitempty"` // section 5.18
+	Dependencies         map[string]*Type    `json:"dependencies,omitempty"`         // section 5.19
+	DependentRequired    map[string][]string `json:"-"`                              // section 5.19
+	PropertyNames        *Type               `json:"propertyNames,omitempty"`        // draft-wright-json-schema-validation-01, section 6.22
+	Enum                 []interface{}       `json:"enum,omitempty"`                 // section 5.20
+	Const                interface{}         `json:"const,omitempty"`                // draft-wright-json-schema-validation-01, section 6.24
+	Type                 string              `json:"type,omitempty"`                 // section 5.21
+	Types                []string            `json:"-"`                              // section 5.21
+	AllOf                []*Type             `json:"allOf,omitempty"`                // section 5.22
+	AnyOf                []*Type             `json:"anyOf,omitempty"`                // section 5.23
+	OneOf                []*Type             `json:"oneOf,omitempty"`                // section 5.24
+	Not                  *Type               `json:"not,omitempty"`                  // section 5.25
+	If                   *Type               `json:"if,omitempty"`                   // draft-handrews-json-schema-validation-00, section 6.6.1
+	Then                 *Type               `json:"then,omitempty"`                 // draft-handrews-json-schema-validation-00, section 6.6.2
+	Else                 *Type               `json:"else,omitempty"`                 // draft-handrews-json-schema-validation-00, section 6.6.3
+	Definitions          Definitions         `json:"definitions,omitempty"`          // section 5.26
 	// RFC draft-wright-json-schema-validation-00, section 6, 7
-	Title       string        `json:"title,omitempty"`       // section 6.1
-	Description string        `json:"description,omitempty"` // section 6.1
-	Default     interface{}   `json:"default,omitempty"`     // section 6.2
-	Format      string        `json:"format,omitempty"`      // section 7
-	Examples    []interface{} `json:"examples,omitempty"`    // section 7.4
+	Title            string        `json:"title,omitempty"`            // section 6.1
+	Description      string        `json:"description,omitempty"`      // section 6.1
+	Default          interface{}   `json:"default,omitempty"`          // section 6.2
+	ReadOnly         bool          `json:"readOnly,omitempty"`         // draft-handrews-json-schema-validation-00, section 10.3
+	WriteOnly        bool          `json:"writeOnly,omitempty"`        // draft-handrews-json-schema-validation-00, section 10.3
+	Format           string        `json:"format,omitempty"`           // section 7
+	ContentEncoding  string        `json:"contentEncoding,omitempty"`  // draft-handrews-json-schema-validation-00, section 8.3
+	ContentMediaType string        `json:"contentMediaType,omitempty"` // draft-handrews-json-schema-validation-00, section 8.4
+	Examples         []interface{} `json:"examples,omitempty"`         // section 7.4
 	// RFC draft-wright-json-schema-hyperschema-00, section 4
 	Media          *Type  `json:"media,omitempty"`          // section 4.3
 	BinaryEncoding string `json:"binaryEncoding,omitempty"` // section 4.3
+	// json-editor extension, see https://github.com/json-editor/json-editor
+	PropertyOrder int `json:"propertyOrder,omitempty"`
+
+	// Extras holds keywords that are not represented by any other field, such
+	// as the draft-04 "id" or vendor extensions. They are marshaled inline.
+	Extras map[string]interface{} `json:"-"`
+
+	// boolean is set for the boolean schemas true and false.
+	boolean *bool
+
+	// nullConst and nullDefault are set for a const or default of null,
+	// which a nil Const or Default would otherwise omit.
+	nullConst, nullDefault bool
+}
+
+// TrueSchema returns the boolean schema true, which any instance is valid against.
+func TrueSchema() *Type {
+	b := true
+	return &Type{boolean: &b}
+}
+
+// FalseSchema returns the boolean schema false, which no instance is valid against.
+func FalseSchema() *Type {
+	b := false
+	return &Type{boolean: &b}
+}
+
+// Boolean reports whether t is a boolean schema, and if so, its value.
+func (t *Type) Boolean() (value bool, ok bool) {
+	if t.boolean == nil {
+		return false, false
+	}
+	return *t.boolean, true
+}
+
+// HasConst reports whether t has a const keyword, whose value may be null.
+func (t *Type) HasConst() bool {
+	return t.Const != nil || t.nullConst
+}
+
+// SetConst sets the const keyword of t to value, which is null if nil.
+func (t *Type) SetConst(value interface{}) {
+	t.Const, t.nullConst = value, value == nil
+}
+
+// DeleteConst removes the const keyword of t.
+func (t *Type) DeleteConst() {
+	t.Const, t.nullConst = nil, false
+}
+
+// HasDefault reports whether t has a default keyword, whose value may be
+// null.
+func (t *Type) HasDefault() bool {
+	return t.Default != nil || t.nullDefault
+}
+
+// SetDefault sets the default keyword of t to value, which is null if nil.
+func (t *Type) SetDefault(value interface{}) {
+	t.Default, t.nullDefault = value, value == nil
+}
+
+// DeleteDefault removes the default keyword of t.
+func (t *Type) DeleteDefault() {
+	t.Default, t.nullDefault = nil, false
 }
 
 // Reflect reflects to Schema from a value using the default Reflector
@@ -107,6 +191,48 @@ type Reflector struct {
 
 	// TypeMapper is a function that can be used to map custom Go types to jsconschema types.
 	TypeMapper func(reflect.Type) *Type
+
+	// PropertyOrder will cause the Reflector to add a propertyOrder keyword to
+	// each property of a struct type, numbering them from 1 in struct field
+	// order. This is used by UI tools such as json-editor that otherwise
+	// ignore the order of keys in the schema.
+	PropertyOrder bool
+
+	// InlineRefs will cause the Reflector to replace references to the
+	// definitions of struct types with copies of the definitions, as done by
+	// Inline. References are kept only for recursive types.
+	InlineRefs bool
+
+	// Structural will cause the Reflector to generate a Kubernetes structural
+	// schema, as done by Structural, for the openAPIV3Schema of a
+	// CustomResourceDefinition. Interface types are reflected as values whose
+	// unknown fields are preserved, rather than as objects.
+	Structural bool
+
+	// PreferYAMLTags will cause the Reflector to name properties after yaml
+	// tags, ignoring json tags, as gopkg.in/yaml.v3 names the keys of YAML
+	// documents: fields without a yaml tag are named in lowercase, embedded
+	// structs are properties of their own, and only fields tagged inline are
+	// inlined. An inlined map takes the additional properties of the struct.
+	// The flow option is accepted; it only changes the layout of the YAML.
+	PreferYAMLTags bool
+
+	// IntOrStringTypes defines a slice of types that are marshaled as either
+	// an integer or a string, such as the IntOrString type of Kubernetes,
+	// which is known without being listed here.
+	IntOrStringTypes []interface{}
+
+	// Cache holds the validators that Unmarshal and ValidateValue compile
+	// from the schemas of types, so that each type is reflected and compiled
+	// once. Reflectors with the same options may share a cache, but the
+	// options of a reflector must not change once it has used one. If Cache
+	// is nil, the schema is reflected and compiled on each call.
+	Cache *ValidatorCache
+
+	// Resolver, if not nil, holds the options that Unmarshal and
+	// ValidateValue validate with, such as StrictFormats. Its documents are
+	// not used, as reflected schemas have no external references.
+	Resolver *Resolver
 }
 
 // Reflect reflects to Schema from a value.
@@ -121,22 +247,32 @@ func (r *Reflector) ReflectFromType(t reflect.Type) *Schema {
 		st := &Type{
 			Version:              Version,
 			Type:                 "object",
-			Properties:           map[string]*Type{},
-			AdditionalProperties: []byte("false"),
+			Properties:           NewProperties(),
+			AdditionalProperties: FalseSchema(),
 		}
 		if r.AllowAdditionalProperties {
-			st.AdditionalProperties = []byte("true")
+			st.AdditionalProperties = TrueSchema()
 		}
 		r.reflectStructFields(st, definitions, t)
 		r.reflectStruct(definitions, t)
 		delete(definitions, t.Name())
-		return &Schema{Type: st, Definitions: definitions}
+		return r.finish(&Schema{Type: st, Definitions: definitions})
 	}
 
 	s := &Schema{
 		Type:        r.reflectTypeToSchema(definitions, t),
 		Definitions: definitions,
 	}
+	return r.finish(s)
+}
+
+// finish applies the transforms requested by the Reflector options to s.
+func (r *Reflector) finish(s *Schema) *Schema {
+	if r.Structural {
+		Structural(s)
+	} else if r.InlineRefs {
+		Inline(s)
+	}
 	return s
 }
 
@@ -184,6 +320,13 @@ func (r *Reflector) reflectTypeToSchema(definitions Definitions, t reflect.Type)
 		}
 	}
 
+	if r.isIntOrString(t) {
+		return &Type{AnyOf: []*Type{
+			{Type: "integer"},
+			{Type: "string"},
+		}}
+	}
+
 	// Defined format types for JSON Schema Validation
 	// RFC draft-wright-json-schema-validation-00, section 7.3
 	// TODO email RFC section 7.3.2, hostname RFC section 7.3.3, uriref RFC section 7.3.7
@@ -218,8 +361,8 @@ func (r *Reflector) reflectTypeToSchema(definitions Definitions, t reflect.Type)
 	case reflect.Slice, reflect.Array:
 		returnType := &Type{}
 		if t.Kind() == reflect.Array {
-			returnType.MinItems = t.Len()
-			returnType.MaxItems = returnType.MinItems
+			n := t.Len()
+			returnType.MinItems, returnType.MaxItems = n, &n
 		}
 		switch t {
 		case byteSliceType:
@@ -233,9 +376,12 @@ func (r *Reflector) reflectTypeToSchema(definitions Definitions, t reflect.Type)
 		}
 
 	case reflect.Interface:
+		if r.Structural {
+			return preserveUnknownFields("")
+		}
 		return &Type{
 			Type:                 "object",
-			AdditionalProperties: []byte("true"),
+			AdditionalProperties: TrueSchema(),
 		}
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
@@ -257,14 +403,28 @@ func (r *Reflector) reflectTypeToSchema(definitions Definitions, t reflect.Type)
 	panic("unsupported type " + t.String())
 }
 
+// isIntOrString reports whether t is marshaled as either an integer or a
+// string.
+func (r *Reflector) isIntOrString(t reflect.Type) bool {
+	if t.PkgPath() == "k8s.io/apimachinery/pkg/util/intstr" && t.Name() == "IntOrString" {
+		return true
+	}
+	for _, typ := range r.IntOrStringTypes {
+		if reflect.TypeOf(typ) == t {
+			return true
+		}
+	}
+	return false
+}
+
 // Refects a struct to a JSON Schema type.
 func (r *Reflector) reflectStruct(definitions Definitions, t reflect.Type) *Type {
 	for _, ignored := range r.IgnoredTypes {
 		if reflect.TypeOf(ignored) == t {
 			st := &Type{
 				Type:                 "object",
-				Properties:           map[string]*Type{},
-				AdditionalProperties: []byte("true"),
+				Properties:           NewProperties(),
+				AdditionalProperties: TrueSchema(),
 			}
 			definitions[t.Name()] = st
 
@@ -277,11 +437,11 @@ func (r *Reflector) reflectStruct(definitions Definitions, t reflect.Type) *Type
 	}
 	st := &Type{
 		Type:                 "object",
-		Properties:           map[string]*Type{},
-		AdditionalProperties: []byte("false"),
+		Properties:           NewProperties(),
+		AdditionalProperties: FalseSchema(),
 	}
 	if r.AllowAdditionalProperties {
-		st.AdditionalProperties = []byte("true")
+		st.AdditionalProperties = TrueSchema()
 	}
 	definitions[t.Name()] = st
 	r.reflectStructFields(st, definitions, t)
@@ -305,15 +465,22 @@ func (r *Reflector) reflectStructFields(st *Type, definitions Definitions, t ref
 		// if anonymous and exported type should be processed recursively
 		// current type should inherit properties of anonymous one
 		if name == "" {
-			if f.Anonymous && !exist {
-				r.reflectStructFields(st, definitions, f.Type)
+			if r.inlined(f, exist) {
+				if r.PreferYAMLTags && f.Type.Kind() == reflect.Map {
+					st.AdditionalProperties = r.reflectTypeToSchema(definitions, f.Type.Elem())
+				} else {
+					r.reflectStructFields(st, definitions, f.Type)
+				}
 			}
 			continue
 		}
 
 		property := r.reflectTypeToSchema(definitions, f.Type)
 		property.structKeywordsFromTags(f)
-		st.Properties[name] = property
+		st.Properties.Set(name, property)
+		if r.PropertyOrder {
+			property.PropertyOrder = st.Properties.index(name) + 1
+		}
 		if required {
 			st.Required = append(st.Required, name)
 		}
@@ -322,8 +489,10 @@ func (r *Reflector) reflectStructFields(st *Type, definitions Definitions, t ref
 
 func (t *Type) structKeywordsFromTags(f reflect.StructField) {
 	t.Description = f.Tag.Get("jsonschema_description")
-	tags := strings.Split(f.Tag.Get("jsonschema"), ",")
+	t.extraKeywords(f.Name, f.Tag.Get("jsonschema_extras"))
+	tags := splitTag(f.Tag.Get("jsonschema"))
 	t.genericKeywords(tags)
+	t.valueKeywords(tags, f.Type)
 	switch t.Type {
 	case "string":
 		t.stringKeywords(tags)
@@ -336,10 +505,54 @@ func (t *Type) structKeywordsFromTags(f reflect.StructField) {
 	}
 }
 
+// extraKeywords reads the keywords of a jsonschema_extras tag into Extras.
+// Values that are JSON literals are decoded, and others kept as strings. A
+// keyword given more than once takes the array of its values. It panics if a
+// keyword is one represented by a field of Type, which Extras cannot set.
+func (t *Type) extraKeywords(field, tag string) {
+	if tag == "" {
+		return
+	}
+	repeated := map[string]bool{}
+	for _, option := range splitTag(tag) {
+		nameValue := strings.SplitN(option, "=", 2)
+		if len(nameValue) != 2 || nameValue[0] == "" {
+			continue
+		}
+		name := nameValue[0]
+		if keywords[name] {
+			panic("jsonschema_extras of field " + field + " sets reserved keyword " + strconv.Quote(name))
+		}
+		var value interface{}
+		if err := decodeJSON([]byte(nameValue[1]), &value); err != nil {
+			value = nameValue[1]
+		}
+		if t.Extras == nil {
+			t.Extras = map[string]interface{}{}
+		}
+		if previous, ok := t.Extras[name]; !ok {
+			t.Extras[name] = value
+		} else if repeated[name] {
+			t.Extras[name] = append(previous.([]interface{}), value)
+		} else {
+			repeated[name] = true
+			t.Extras[name] = []interface{}{previous, value}
+		}
+	}
+}
+
+// setExtra sets the keyword name of Extras to value.
+func (t *Type) setExtra(name string, value interface{}) {
+	if t.Extras == nil {
+		t.Extras = map[string]interface{}{}
+	}
+	t.Extras[name] = value
+}
+
 // read struct tags for generic keyworks
 func (t *Type) genericKeywords(tags []string) {
 	for _, tag := range tags {
-		nameValue := strings.Split(tag, "=")
+		nameValue := strings.SplitN(tag, "=", 2)
 		if len(nameValue) == 2 {
 			name, val := nameValue[0], nameValue[1]
 			switch name {
@@ -355,7 +568,7 @@ func (t *Type) genericKeywords(tags []string) {
 // read struct tags for string type keyworks
 func (t *Type) stringKeywords(tags []string) {
 	for _, tag := range tags {
-		nameValue := strings.Split(tag, "=")
+		nameValue := strings.SplitN(tag, "=", 2)
 		if len(nameValue) == 2 {
 			name, val := nameValue[0], nameValue[1]
 			switch name {
@@ -364,19 +577,13 @@ func (t *Type) stringKeywords(tags []string) {
 				t.MinLength = i
 			case "maxLength":
 				i, _ := strconv.Atoi(val)
-				t.MaxLength = i
+				t.MaxLength = &i
 			case "pattern":
 				t.Pattern = val
 			case "format":
-				switch val {
-				case "date-time", "email", "hostname", "ipv4", "ipv6", "uri":
-					t.Format = val
-					break
-				}
-			case "default":
-				t.Default = val
-			case "example":
-				t.Examples = append(t.Examples, val)
+				t.Format = val
+			case "enum":
+				t.Enum = append(t.Enum, val)
 			}
 		}
 	}
@@ -385,31 +592,23 @@ func (t *Type) stringKeywords(tags []string) {
 // read struct tags for numberic type keyworks
 func (t *Type) numbericKeywords(tags []string) {
 	for _, tag := range tags {
-		nameValue := strings.Split(tag, "=")
+		nameValue := strings.SplitN(tag, "=", 2)
 		if len(nameValue) == 2 {
 			name, val := nameValue[0], nameValue[1]
 			switch name {
 			case "multipleOf":
-				i, _ := strconv.Atoi(val)
-				t.MultipleOf = i
+				t.MultipleOf = parseNumber(val)
 			case "minimum":
-				i, _ := strconv.Atoi(val)
-				t.Minimum = i
+				t.Minimum = parseNumber(val)
 			case "maximum":
-				i, _ := strconv.Atoi(val)
-				t.Maximum = i
+				t.Maximum = parseNumber(val)
 			case "exclusiveMaximum":
-				b, _ := strconv.ParseBool(val)
-				t.ExclusiveMaximum = b
+				t.ExclusiveMaximum = parseBoolOrNumber(val)
 			case "exclusiveMinimum":
-				b, _ := strconv.ParseBool(val)
-				t.ExclusiveMinimum = b
-			case "default":
-				i, _ := strconv.Atoi(val)
-				t.Default = i
-			case "example":
-				if i, err := strconv.Atoi(val); err == nil {
-					t.Examples = append(t.Examples, i)
+				t.ExclusiveMinimum = parseBoolOrNumber(val)
+			case "enum":
+				if n := parseNumber(val); n != "" {
+					t.Enum = append(t.Enum, n)
 				}
 			}
 		}
@@ -434,9 +633,8 @@ func (t *Type) numbericKeywords(tags []string) {
 
 // read struct tags for array type keyworks
 func (t *Type) arrayKeywords(tags []string) {
-	var defaultValues []interface{}
 	for _, tag := range tags {
-		nameValue := strings.Split(tag, "=")
+		nameValue := strings.SplitN(tag, "=", 2)
 		if len(nameValue) == 2 {
 			name, val := nameValue[0], nameValue[1]
 			switch name {
@@ -445,17 +643,185 @@ func (t *Type) arrayKeywords(tags []string) {
 				t.MinItems = i
 			case "maxItems":
 				i, _ := strconv.Atoi(val)
-				t.MaxItems = i
+				t.MaxItems = &i
 			case "uniqueItems":
 				t.UniqueItems = true
-			case "default":
-				defaultValues = append(defaultValues, val)
+			case "listType":
+				t.setExtra("x-kubernetes-list-type", val)
+			case "listMapKey":
+				keys, _ := t.Extras["x-kubernetes-list-map-keys"].([]interface{})
+				t.setExtra("x-kubernetes-list-map-keys", append(keys, val))
+			}
+		}
+	}
+}
+
+// read struct tags for default and example values, which are parsed as
+// values of the type of the field: strings, numbers and booleans as such,
+// and maps, structs and interfaces as JSON literals. The default of a slice
+// or array is either a JSON array literal, or the elements of the array given
+// by repeated default tags; each example is a JSON array literal.
+func (t *Type) valueKeywords(tags []string, typ reflect.Type) {
+	var defaultItems []interface{}
+	for _, tag := range tags {
+		nameValue := strings.SplitN(tag, "=", 2)
+		if len(nameValue) != 2 {
+			continue
+		}
+		name, val := nameValue[0], nameValue[1]
+		switch name {
+		case "default":
+			if isListType(typ) && !strings.HasPrefix(val, "[") {
+				if value, ok := parseTagValue(listElem(typ), val); ok {
+					defaultItems = append(defaultItems, value)
+				}
+			} else if value, ok := parseTagValue(typ, val); ok {
+				t.SetDefault(value)
+			}
+		case "example":
+			if value, ok := parseTagValue(typ, val); ok {
+				t.Examples = append(t.Examples, value)
+			}
+		}
+	}
+	if len(defaultItems) > 0 {
+		t.Default = defaultItems
+	}
+}
+
+// parseTagValue parses a default or example value of the given type.
+func parseTagValue(typ reflect.Type, val string) (interface{}, bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case timeType, ipType, uriType, byteSliceType:
+		return val, true
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return val, true
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		return b, err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err := strconv.ParseInt(val, 10, 64)
+		return json.Number(val), err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err := strconv.ParseUint(val, 10, 64)
+		return json.Number(val), err == nil
+	case reflect.Float32, reflect.Float64:
+		n := parseNumber(val)
+		return n, n != ""
+	}
+	if typ.Implements(protoEnumType) {
+		if n := parseNumber(val); n != "" {
+			return n, true
+		}
+		return val, true
+	}
+	var value interface{}
+	if err := decodeJSON([]byte(val), &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// isListType reports whether typ is reflected as a JSON array.
+func isListType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ != byteSliceType && typ != ipType
+}
+
+// listElem returns the element type of a slice or array type, or of a
+// pointer to one.
+func listElem(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Elem()
+}
+
+// splitTag splits a jsonschema tag into its comma-separated options. The
+// value of an option may be a JSON array or object literal containing
+// commas, such as default=[1,2].
+func splitTag(tag string) []string {
+	var options []string
+	for {
+		end := strings.IndexByte(tag, ',')
+		if eq := strings.IndexByte(tag, '='); eq >= 0 && (end < 0 || eq < end) {
+			if n := jsonLiteralLength(tag[eq+1:]); n > 0 {
+				if rest := tag[eq+1+n:]; rest == "" {
+					end = -1
+				} else if rest[0] == ',' {
+					end = eq + 1 + n
+				}
+			}
+		}
+		if end < 0 {
+			return append(options, tag)
+		}
+		options = append(options, tag[:end])
+		tag = tag[end+1:]
+	}
+}
+
+// jsonLiteralLength returns the length of the JSON array or object literal at
+// the start of s, or 0 if there is none.
+func jsonLiteralLength(s string) int {
+	if s == "" || (s[0] != '[' && s[0] != '{') {
+		return 0
+	}
+	depth, inString := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				if json.Valid([]byte(s[:i+1])) {
+					return i + 1
+				}
+				return 0
 			}
 		}
 	}
-	if len(defaultValues) > 0 {
-		t.Default = defaultValues
+	return 0
+}
+
+// parseNumber returns val as a JSON number, or "" if it is not one. Numbers
+// that strconv accepts but JSON does not, such as Inf, NaN, 0x10 and 1_000,
+// are not.
+func parseNumber(val string) json.Number {
+	last := len(val) - 1
+	if last < 0 || (val[0] != '-' && !isDigit(val[0])) || !isDigit(val[last]) || !json.Valid([]byte(val)) {
+		return ""
+	}
+	return json.Number(val)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseBoolOrNumber returns val as the JSON value of a draft-04 boolean or a
+// draft-06 numeric exclusiveMaximum or exclusiveMinimum. False is omitted.
+func parseBoolOrNumber(val string) json.RawMessage {
+	if b, err := strconv.ParseBool(val); err == nil {
+		if b {
+			return json.RawMessage("true")
+		}
+		return nil
 	}
+	return json.RawMessage(parseNumber(val))
 }
 
 func requiredFromJSONTags(tags []string) bool {
@@ -483,8 +849,10 @@ func requiredFromJSONSchemaTags(tags []string) bool {
 	return false
 }
 
+// ignoredByJSONTags reports whether a json tag ignores its field. As in
+// encoding/json, a tag of "-," names the field "-" instead.
 func ignoredByJSONTags(tags []string) bool {
-	return tags[0] == "-"
+	return len(tags) == 1 && tags[0] == "-"
 }
 
 func ignoredByJSONSchemaTags(tags []string) bool {
@@ -492,10 +860,10 @@ func ignoredByJSONSchemaTags(tags []string) bool {
 }
 
 func (r *Reflector) reflectFieldName(f reflect.StructField) (string, bool, bool) {
-	jsonTags, exist := f.Tag.Lookup("json")
-	if !exist {
-		jsonTags = f.Tag.Get("yaml")
+	if r.PreferYAMLTags {
+		return r.reflectYAMLFieldName(f)
 	}
+	jsonTags, exist := r.fieldTag(f)
 
 	jsonTagsList := strings.Split(jsonTags, ",")
 
@@ -503,7 +871,7 @@ func (r *Reflector) reflectFieldName(f reflect.StructField) (string, bool, bool)
 		return "", exist, false
 	}
 
-	jsonSchemaTags := strings.Split(f.Tag.Get("jsonschema"), ",")
+	jsonSchemaTags := splitTag(f.Tag.Get("jsonschema"))
 	if ignoredByJSONSchemaTags(jsonSchemaTags) {
 		return "", exist, false
 	}
@@ -531,3 +899,49 @@ func (r *Reflector) reflectFieldName(f reflect.StructField) (string, bool, bool)
 
 	return name, exist, required
 }
+
+// reflectYAMLFieldName is reflectFieldName for PreferYAMLTags. The fields
+// tagged inline are the only ones without a name that are reported as having
+// no tag, so that they are inlined.
+func (r *Reflector) reflectYAMLFieldName(f reflect.StructField) (string, bool, bool) {
+	yamlTag, _ := r.fieldTag(f)
+	yamlTags := strings.Split(yamlTag, ",")
+	jsonSchemaTags := splitTag(f.Tag.Get("jsonschema"))
+	if ignoredByJSONTags(yamlTags) || ignoredByJSONSchemaTags(jsonSchemaTags) || f.PkgPath != "" {
+		return "", true, false
+	}
+	for _, option := range yamlTags[1:] {
+		if option == "inline" {
+			return "", false, false
+		}
+	}
+
+	required := requiredFromJSONTags(yamlTags)
+	if r.RequiredFromJSONSchemaTags {
+		required = requiredFromJSONSchemaTags(jsonSchemaTags)
+	}
+	name := yamlTags[0]
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	return name, true, required
+}
+
+// fieldTag returns the tag that the field f is named from: its yaml tag for
+// PreferYAMLTags, or else its json tag, or its yaml tag if it has none. It
+// reports whether f has a json tag.
+func (r *Reflector) fieldTag(f reflect.StructField) (string, bool) {
+	if r.PreferYAMLTags {
+		return f.Tag.Get("yaml"), false
+	}
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		return tag, true
+	}
+	return f.Tag.Get("yaml"), false
+}
+
+// inlined reports whether the properties of the field f, given whether it has
+// a tag, are inlined into those of its struct.
+func (r *Reflector) inlined(f reflect.StructField, exist bool) bool {
+	return !exist && (f.Anonymous || r.PreferYAMLTags)
+}
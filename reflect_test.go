@@ -76,6 +76,18 @@ type TestUser struct {
 	Email   string    `json:"email" jsonschema:"format=email"`
 }
 
+type TypedDefaults struct {
+	Enabled  bool              `json:"enabled" jsonschema:"default=true,example=false"`
+	Ratio    float64           `json:"ratio" jsonschema:"default=0.75,example=1.5"`
+	Retries  *uint8            `json:"retries" jsonschema:"default=3"`
+	Ports    []int             `json:"ports" jsonschema:"default=80,default=443,example=[8080,8443]"`
+	Hosts    []string          `json:"hosts" jsonschema:"default=[\"a,b\",\"c\"],minItems=1"`
+	Labels   map[string]string `json:"labels" jsonschema:"default={\"env\":\"dev\",\"tier\":\"web\"},required"`
+	Owner    GrandfatherType   `json:"owner" jsonschema:"example={\"family_name\":\"Doe\"}"`
+	Invalid  int               `json:"invalid" jsonschema:"default=1.5,example=x"`
+	Fallback interface{}       `json:"fallback" jsonschema:"default={\"a\":[1,2]}"`
+}
+
 type CustomTime time.Time
 
 type CustomTypeField struct {
@@ -93,6 +105,9 @@ func TestSchemaGeneration(t *testing.T) {
 		{&TestUser{}, &Reflector{RequiredFromJSONSchemaTags: true}, "fixtures/required_from_jsontags.json"},
 		{&TestUser{}, &Reflector{ExpandedStruct: true}, "fixtures/defaults_expanded_toplevel.json"},
 		{&TestUser{}, &Reflector{IgnoredTypes: []interface{}{GrandfatherType{}}}, "fixtures/ignore_type.json"},
+		{&TestUser{}, &Reflector{PropertyOrder: true}, "fixtures/property_order.json"},
+		{&TestUser{}, &Reflector{InlineRefs: true}, "fixtures/inline_refs.json"},
+		{&TypedDefaults{}, &Reflector{RequiredFromJSONSchemaTags: true}, "fixtures/typed_defaults.json"},
 		{&CustomTypeField{}, &Reflector{
 			TypeMapper: func(i reflect.Type) *Type {
 				if i == reflect.TypeOf(CustomTime{}) {
@@ -124,3 +139,39 @@ func TestSchemaGeneration(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitTag(t *testing.T) {
+	tests := map[string][]string{
+		"":                           {""},
+		"required,minLength=1":       {"required", "minLength=1"},
+		`default=["a,b"],minItems=1`: {`default=["a,b"]`, "minItems=1"},
+		`default={"a":{"b":"}"}}`:    {`default={"a":{"b":"}"}}`},
+		"pattern=[a-z],x":            {"pattern=[a-z]", "x"},
+		"pattern=^[a,b]+$,example=a": {"pattern=^[a", "b]+$", "example=a"},
+		`default=[1,2]x,example=[`:   {"default=[1", "2]x", "example=["},
+	}
+	for tag, expected := range tests {
+		require.Equal(t, expected, splitTag(tag), tag)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	for _, val := range []string{"0", "-1", "1.5", "2e10", "-0.25E-3"} {
+		require.Equal(t, json.Number(val), parseNumber(val), val)
+	}
+	for _, val := range []string{"", "Inf", "+Inf", "-Inf", "NaN", "0x10", "1_000", "+1", ".5", "1.", "01", " 1", "1 ", "1e"} {
+		require.Equal(t, json.Number(""), parseNumber(val), val)
+	}
+}
+
+type NonJSONBounds struct {
+	A float64 `json:"a" jsonschema:"minimum=Inf,maximum=NaN,multipleOf=0x10"`
+	B float64 `json:"b" jsonschema:"exclusiveMaximum=1_000,exclusiveMinimum=-Inf"`
+}
+
+func TestNonJSONBoundsDropped(t *testing.T) {
+	data, err := json.Marshal(Reflect(&NonJSONBounds{}))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "imum")
+	require.NotContains(t, string(data), "multipleOf")
+}
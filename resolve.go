@@ -0,0 +1,336 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrCyclicRef is returned when a chain of $ref values leads back to itself
+// without reaching a schema.
+var ErrCyclicRef = errors.New("jsonschema: cyclic $ref")
+
+// A Loader loads the external schema documents that $ref values point to.
+type Loader interface {
+	// Load returns the document with the given URI. The URI never has a
+	// fragment.
+	Load(uri string) (*Schema, error)
+}
+
+// LoaderFunc adapts a function to a Loader.
+type LoaderFunc func(uri string) (*Schema, error)
+
+// Load calls f(uri).
+func (f LoaderFunc) Load(uri string) (*Schema, error) {
+	return f(uri)
+}
+
+// FSLoader loads JSON documents from a file system. The path of the URI,
+// without any leading slash, is the name of the file in FS; the scheme and
+// host are ignored.
+type FSLoader struct {
+	FS fs.FS
+}
+
+// Load implements Loader.
+func (l *FSLoader) Load(uri string) (*Schema, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	data, err := fs.ReadFile(l.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("jsonschema: %s: %w", uri, err)
+	}
+	return s, nil
+}
+
+// MemoryLoader loads documents from a map keyed by URI.
+type MemoryLoader map[string]*Schema
+
+// Load implements Loader.
+func (l MemoryLoader) Load(uri string) (*Schema, error) {
+	s, ok := l[uri]
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: no schema for %q", uri)
+	}
+	return s, nil
+}
+
+// A Resolver resolves $ref values to the schemas they point to.
+//
+// References are resolved against the base URI of the schema they appear in,
+// which is the URI of its document unless a $id (or draft-04 id) of the schema
+// or of one of its parents says otherwise. The fragment of a reference may be
+// a JSON Pointer or a plain name declared by a $id. Documents not added with
+// AddSchema are loaded on demand through the Loader.
+type Resolver struct {
+	// StrictFormats makes a format without a registered checker an error,
+	// rather than leave it unchecked: Compile returns an error for it, and
+	// Validate reports it as a failure of the strings it applies to, as it
+	// does an invalid pattern. The Resolver of a Reflector passes it on to
+	// Unmarshal and ValidateValue. See RegisterFormat.
+	StrictFormats bool
+
+	loader Loader
+	docs   map[string]*Schema
+	ids    map[string]*Type
+	bases  map[*Type]string
+}
+
+// NewResolver creates a Resolver that loads external documents with loader,
+// which may be nil if all documents are added with AddSchema.
+func NewResolver(loader Loader) *Resolver {
+	return &Resolver{
+		loader: loader,
+		docs:   map[string]*Schema{},
+		ids:    map[string]*Type{},
+		bases:  map[*Type]string{},
+	}
+}
+
+// AddSchema adds the document s with the given URI, which may be relative or
+// empty, and indexes the identifiers declared in it.
+func (r *Resolver) AddSchema(uri string, s *Schema) error {
+	uri, _ = splitFragment(uri)
+	r.docs[uri] = s
+	if s.Type == nil {
+		return nil
+	}
+	base, err := r.index(uri, s.Type, map[*Type]bool{})
+	if err != nil {
+		return err
+	}
+	r.docs[base] = s
+	for _, name := range sortedKeys(s.Definitions) {
+		if _, err := r.index(base, s.Definitions[name], map[*Type]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// index records the base URI of t and its subschemas, and the schemas
+// identified by a $id. It returns the base URI of t.
+func (r *Resolver) index(base string, t *Type, seen map[*Type]bool) (string, error) {
+	if seen[t] {
+		return r.bases[t], nil
+	}
+	seen[t] = true
+	if id := t.id(); id != "" {
+		resolved, err := resolveURI(base, id)
+		if err != nil {
+			return "", err
+		}
+		r.ids[resolved] = t
+		if uri, fragment := splitFragment(resolved); fragment == "" {
+			base = uri
+		}
+	}
+	r.bases[t] = base
+	var err error
+	t.eachSubschema(func(_ []string, sub *Type) {
+		if err == nil {
+			_, err = r.index(base, sub, seen)
+		}
+	})
+	return base, err
+}
+
+// Base returns the base URI of a schema in one of the documents of the
+// resolver, against which the $ref of the schema is resolved.
+func (r *Resolver) Base(t *Type) string {
+	return r.bases[t]
+}
+
+// Deref returns the schema t refers to, following chains of references, or t
+// itself if it is not a reference.
+func (r *Resolver) Deref(t *Type) (*Type, error) {
+	if t.Ref == "" {
+		return t, nil
+	}
+	target, _, err := r.Resolve(r.bases[t], t.Ref)
+	return target, err
+}
+
+// Resolve resolves ref against base, following chains of references, and
+// returns the schema it points to along with the base URI of that schema.
+func (r *Resolver) Resolve(base, ref string) (*Type, string, error) {
+	seen := map[string]bool{}
+	for {
+		uri, err := resolveURI(base, ref)
+		if err != nil {
+			return nil, "", err
+		}
+		if seen[uri] {
+			return nil, "", fmt.Errorf("%w: %s", ErrCyclicRef, uri)
+		}
+		seen[uri] = true
+		target, err := r.lookup(uri)
+		if err != nil {
+			return nil, "", err
+		}
+		if target.Ref == "" {
+			return target, r.bases[target], nil
+		}
+		base, ref = r.bases[target], target.Ref
+	}
+}
+
+// lookup returns the schema with the given absolute URI.
+func (r *Resolver) lookup(uri string) (*Type, error) {
+	if t, ok := r.ids[uri]; ok {
+		return t, nil
+	}
+	docURI, fragment := splitFragment(uri)
+	var root *Type
+	var definitions Definitions
+	if t, ok := r.ids[docURI]; ok && r.docs[docURI] == nil {
+		root = t
+	} else {
+		doc, err := r.document(docURI)
+		if err != nil {
+			return nil, err
+		}
+		root, definitions = doc.Type, doc.Definitions
+	}
+	if fragment == "" {
+		return root, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		return nil, fmt.Errorf("jsonschema: no schema with id %q", uri)
+	}
+
+	tokens := strings.Split(fragment[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = unescapePointerToken(token)
+	}
+	t := root
+	if len(tokens) >= 2 && tokens[0] == "definitions" && definitions[tokens[1]] != nil {
+		t, tokens = definitions[tokens[1]], tokens[2:]
+	}
+	for len(tokens) > 0 && t != nil {
+		if value, ok := t.Extras[tokens[0]]; ok {
+			return r.extra(uri, r.bases[t], value, tokens[1:])
+		}
+		sub, n := t.subschema(tokens)
+		t, tokens = sub, tokens[n:]
+	}
+	if t == nil {
+		return nil, fmt.Errorf("jsonschema: no schema at %q", uri)
+	}
+	return t, nil
+}
+
+// extra returns the schema at the given tokens within the value of an unknown
+// keyword, such as the draft 2019-09 "$defs". The schema is decoded once and
+// indexed under uri.
+func (r *Resolver) extra(uri, base string, value interface{}, tokens []string) (*Type, error) {
+	for _, token := range tokens {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("jsonschema: no schema at %q", uri)
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("jsonschema: no schema at %q", uri)
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	t := &Type{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("jsonschema: %s: %w", uri, err)
+	}
+	r.ids[uri] = t
+	if _, err := r.index(base, t, map[*Type]bool{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// document returns the document with the given URI, loading it if needed.
+func (r *Resolver) document(uri string) (*Schema, error) {
+	if doc, ok := r.docs[uri]; ok {
+		return doc, nil
+	}
+	if r.loader == nil {
+		return nil, fmt.Errorf("jsonschema: no schema for %q", uri)
+	}
+	doc, err := r.loader.Load(uri)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.AddSchema(uri, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// id returns the $id of t, or its draft-04 id.
+func (t *Type) id() string {
+	if t.ID != "" {
+		return t.ID
+	}
+	if id, ok := t.Extras["id"].(string); ok {
+		return id
+	}
+	return ""
+}
+
+// resolveURI resolves ref against base. Unlike url.URL.ResolveReference, a
+// relative base yields a relative URI.
+func resolveURI(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if b.IsAbs() || b.Host != "" || strings.HasPrefix(b.Path, "/") || u.IsAbs() || strings.HasPrefix(u.Path, "/") {
+		return b.ResolveReference(u).String(), nil
+	}
+	b.Path = "/" + b.Path
+	resolved := b.ResolveReference(u)
+	resolved.Path = strings.TrimPrefix(resolved.Path, "/")
+	return resolved.String(), nil
+}
+
+// splitFragment splits uri into the URI without its fragment and the fragment.
+func splitFragment(uri string) (string, string) {
+	i := strings.IndexByte(uri, '#')
+	if i < 0 {
+		return uri, ""
+	}
+	fragment, err := url.PathUnescape(uri[i+1:])
+	if err != nil {
+		fragment = uri[i+1:]
+	}
+	return uri[:i], fragment
+}
+
+func unescapePointerToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+}
+
+func escapePointerToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,103 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverFSLoader(t *testing.T) {
+	loader := &FSLoader{FS: os.DirFS("fixtures/refs")}
+	root, err := loader.Load("order.json")
+	require.NoError(t, err)
+	r := NewResolver(loader)
+	require.NoError(t, r.AddSchema("order.json", root))
+
+	total, _ := root.Properties.Get("total")
+	money, err := r.Deref(total)
+	require.NoError(t, err)
+	require.Equal(t, "common.json", r.Base(money))
+	amount, _ := money.Properties.Get("amount")
+	require.Equal(t, "number", amount.Type)
+
+	currency, _ := money.Properties.Get("currency")
+	target, err := r.Deref(currency)
+	require.NoError(t, err)
+	require.Equal(t, "^[A-Z]{3}$", target.Pattern)
+
+	customer, _ := root.Properties.Get("customer")
+	target, err = r.Deref(customer)
+	require.NoError(t, err)
+	require.Equal(t, "types/customer.json", r.Base(target))
+
+	balance, _ := target.Properties.Get("balance")
+	resolved, err := r.Deref(balance)
+	require.NoError(t, err)
+	require.Equal(t, money, resolved)
+
+	referrer, _ := target.Properties.Get("referrer")
+	resolved, err = r.Deref(referrer)
+	require.NoError(t, err)
+	require.Equal(t, target, resolved)
+
+	line := root.Definitions["Line"]
+	sku, _ := line.Properties.Get("sku")
+	resolved, err = r.Deref(sku)
+	require.NoError(t, err)
+	require.Equal(t, "#sku", resolved.ID)
+}
+
+func TestResolverMemoryLoader(t *testing.T) {
+	loader := MemoryLoader{
+		"http://example.com/schemas/a.json": mustSchema(t, `{"definitions": {"a~b/c": {"type": "integer"}}}`),
+	}
+	r := NewResolver(loader)
+	root := mustSchema(t, `{"$id": "http://example.com/schemas/root.json", "$ref": "a.json#/definitions/a~0b~1c"}`)
+	require.NoError(t, r.AddSchema("", root))
+
+	target, err := r.Deref(root.Type)
+	require.NoError(t, err)
+	require.Equal(t, "integer", target.Type)
+
+	_, _, err = r.Resolve("http://example.com/schemas/root.json", "missing.json")
+	require.Error(t, err)
+}
+
+func TestResolverCyclicRef(t *testing.T) {
+	loader := MemoryLoader{
+		"a.json": mustSchema(t, `{"$ref": "b.json"}`),
+		"b.json": mustSchema(t, `{"$ref": "a.json"}`),
+	}
+	r := NewResolver(loader)
+	_, _, err := r.Resolve("", "a.json")
+	require.True(t, errors.Is(err, ErrCyclicRef))
+}
+
+func TestResolverExtras(t *testing.T) {
+	r := NewResolver(nil)
+	root := mustSchema(t, `{"$defs": {"name": {"type": "string"}}, "$ref": "#/$defs/name"}`)
+	require.NoError(t, r.AddSchema("", root))
+	target, err := r.Deref(root.Type)
+	require.NoError(t, err)
+	require.Equal(t, "string", target.Type)
+}
+
+func TestFSLoaderMapFS(t *testing.T) {
+	loader := &FSLoader{FS: fstest.MapFS{
+		"schemas/a.json": &fstest.MapFile{Data: []byte(`{"type": "boolean"}`)},
+	}}
+	s, err := loader.Load("file:///schemas/a.json")
+	require.NoError(t, err)
+	require.Equal(t, "boolean", s.Type.Type)
+}
+
+func mustSchema(t *testing.T, data string) *Schema {
+	t.Helper()
+	s := &Schema{}
+	require.NoError(t, json.Unmarshal([]byte(data), s))
+	return s
+}
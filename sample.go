@@ -0,0 +1,654 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SampleGenerator generates sample instances of a schema, such as example
+// payloads for documentation and contract tests.
+//
+// A schema with examples or a default yields one of them. Otherwise a value
+// is made up that respects the type, enum and const of the schema, the
+// bounds of its strings, numbers and arrays, its pattern and format, and the
+// properties it requires, following references. Samples are checked against
+// the schema, and generated again if they do not satisfy it.
+type SampleGenerator struct {
+	// Seed seeds the random choices of the generator. The same seed yields
+	// the same samples of the same schema.
+	Seed int64
+
+	// MaxDepth is the depth of nesting beyond which objects only have their
+	// required properties, and arrays their minimum number of items, so that
+	// samples of recursive schemas end. It defaults to 4.
+	MaxDepth int
+}
+
+// sampleAttempts is the number of samples generated before giving up on
+// finding one that satisfies the schema.
+const sampleAttempts = 20
+
+// Generate returns an indented JSON encoding of a sample of s.
+func (g *SampleGenerator) Generate(s *Schema) ([]byte, error) {
+	sample, err := g.Sample(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(sample, "", "  ")
+}
+
+// Sample returns a sample of s, as a value decoded from JSON would be, with
+// numbers as json.Number. References to other documents cannot be resolved.
+func (g *SampleGenerator) Sample(s *Schema) (interface{}, error) {
+	gen, err := newSampler(s, g.Seed, g.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	return gen.validSample(s.Type)
+}
+
+// sampleRequiredDepth is the depth of nesting beyond MaxDepth that required
+// properties and minimum items can reach before a schema is deemed to require
+// infinitely nested values.
+const sampleRequiredDepth = 32
+
+// sampler generates the samples of the schemas of a resolver.
+type sampler struct {
+	resolver *Resolver
+	rand     *rand.Rand
+	maxDepth int
+}
+
+// newSampler returns a sampler of the schemas of s, seeded with seed.
+func newSampler(s *Schema, seed int64, maxDepth int) (*sampler, error) {
+	r := NewResolver(nil)
+	if err := r.AddSchema("", s); err != nil {
+		return nil, err
+	}
+	if maxDepth <= 0 {
+		maxDepth = 4
+	}
+	return &sampler{resolver: r, rand: rand.New(rand.NewSource(seed)), maxDepth: maxDepth}, nil
+}
+
+// validSample returns a sample of t that satisfies it.
+func (g *sampler) validSample(t *Type) (interface{}, error) {
+	var invalid error
+	for i := 0; i < sampleAttempts; i++ {
+		sample, err := g.sample(t, 0)
+		if err != nil {
+			return nil, err
+		}
+		if invalid = g.resolver.Validate(t, sample); invalid == nil {
+			return sample, nil
+		}
+	}
+	return nil, fmt.Errorf("jsonschema: no sample satisfying the schema found: %w", invalid)
+}
+
+// sample returns a sample of t, nested depth levels deep in the instance.
+func (g *sampler) sample(t *Type, depth int) (interface{}, error) {
+	if t == nil {
+		return nil, nil
+	}
+	if depth > g.maxDepth+sampleRequiredDepth {
+		return nil, fmt.Errorf("jsonschema: schema requires values nested more than %d levels deep", g.maxDepth+sampleRequiredDepth)
+	}
+	if _, ok := t.Boolean(); ok {
+		return nil, nil
+	}
+	if t.Ref != "" {
+		target, err := g.resolver.Deref(t)
+		if err != nil {
+			return nil, err
+		}
+		return g.sample(target, depth)
+	}
+
+	switch {
+	case len(t.Examples) > 0:
+		return copyJSON(t.Examples[g.rand.Intn(len(t.Examples))]), nil
+	case t.HasDefault():
+		return copyJSON(t.Default), nil
+	case t.HasConst():
+		return copyJSON(t.Const), nil
+	case len(t.Enum) > 0:
+		return copyJSON(t.Enum[g.rand.Intn(len(t.Enum))]), nil
+	}
+
+	var value interface{}
+	var err error
+	switch g.sampleType(t) {
+	case "object":
+		value, err = g.sampleObject(t, depth)
+	case "array":
+		value, err = g.sampleArray(t, depth)
+	case "string":
+		value, err = g.sampleString(t)
+	case "integer":
+		value = g.sampleNumber(t, true)
+	case "number":
+		value = g.sampleNumber(t, false)
+	case "boolean":
+		value = g.rand.Intn(2) == 0
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Subschemas that apply to the value as a whole add to it: the
+	// properties of objects are merged, and other values are kept.
+	parts := append([]*Type{}, t.AllOf...)
+	if len(t.AnyOf) > 0 {
+		parts = append(parts, t.AnyOf[g.rand.Intn(len(t.AnyOf))])
+	}
+	if len(t.OneOf) > 0 {
+		parts = append(parts, t.OneOf[g.rand.Intn(len(t.OneOf))])
+	}
+	for _, part := range parts {
+		sub, err := g.sample(part, depth)
+		if err != nil {
+			return nil, err
+		}
+		value = mergeSamples(value, sub)
+	}
+	return value, nil
+}
+
+// sampleType returns the type of the samples of t: one of its types,
+// preferring those other than null, or the type its keywords apply to.
+func (g *sampler) sampleType(t *Type) string {
+	var types []string
+	for _, typ := range t.typeList() {
+		if typ != "null" {
+			types = append(types, typ)
+		}
+	}
+	switch {
+	case len(types) > 0:
+		return types[g.rand.Intn(len(types))]
+	case len(t.typeList()) > 0:
+		return "null"
+	case t.Properties.Len() > 0 || len(t.Required) > 0 || len(t.PatternProperties) > 0 || t.AdditionalProperties != nil ||
+		t.MinProperties > 0 || t.MaxProperties != nil:
+		return "object"
+	case t.Items != nil || t.TupleItems != nil || t.MinItems > 0 || t.MaxItems != nil || t.Contains != nil:
+		return "array"
+	case t.MinLength > 0 || t.MaxLength != nil || t.Pattern != "" || t.Format != "":
+		return "string"
+	case t.Minimum != "" || t.Maximum != "" || len(t.ExclusiveMinimum) > 0 || len(t.ExclusiveMaximum) > 0 || t.MultipleOf != "":
+		return "number"
+	}
+	return ""
+}
+
+func (g *sampler) sampleObject(t *Type, depth int) (interface{}, error) {
+	object := map[string]interface{}{}
+	required := map[string]bool{}
+	for _, name := range t.Required {
+		required[name] = true
+	}
+	add := func(name string) error {
+		if _, ok := object[name]; ok {
+			return nil
+		}
+		value, err := g.sample(g.propertySchema(t, name), depth+1)
+		object[name] = value
+		return err
+	}
+
+	var optional []string
+	for _, name := range t.Properties.Keys() {
+		if !required[name] {
+			if depth >= g.maxDepth {
+				continue
+			}
+			optional = append(optional, name)
+		}
+		if err := add(name); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range t.Required {
+		if err := add(name); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedStringKeys(t.DependentRequired) {
+		if _, ok := object[name]; ok {
+			for _, dependency := range t.DependentRequired[name] {
+				if err := add(dependency); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for i := 1; len(object) < t.MinProperties && !t.closed(); i++ {
+		if err := add("property" + strconv.Itoa(i)); err != nil {
+			return nil, err
+		}
+	}
+	for t.MaxProperties != nil && len(object) > *t.MaxProperties && len(optional) > 0 {
+		delete(object, optional[len(optional)-1])
+		optional = optional[:len(optional)-1]
+	}
+	return object, nil
+}
+
+// propertySchema returns the schema of the property name of t, or nil if it
+// is not constrained.
+func (g *sampler) propertySchema(t *Type, name string) *Type {
+	if sub, ok := t.Properties.Get(name); ok {
+		return sub
+	}
+	for _, pattern := range sortedKeys(t.PatternProperties) {
+		if re, err := compilePattern(pattern); err == nil && re.MatchString(name) {
+			return t.PatternProperties[pattern]
+		}
+	}
+	return t.AdditionalProperties
+}
+
+func (g *sampler) sampleArray(t *Type, depth int) (interface{}, error) {
+	n := t.MinItems
+	if depth < g.maxDepth {
+		max := n + 2
+		if t.MaxItems != nil && max > *t.MaxItems {
+			max = *t.MaxItems
+		}
+		if max > n {
+			n += g.rand.Intn(max - n + 1)
+		}
+	}
+	if t.TupleItems != nil && t.AdditionalItems == nil && n > len(t.TupleItems) {
+		n = len(t.TupleItems)
+	}
+
+	items := make([]interface{}, 0, n)
+	seen := map[string]bool{}
+	for i := 0; i < n; i++ {
+		sub, _ := itemSchema(t, i, "")
+		var item interface{}
+		// Unique items are generated again a few times until they differ.
+		for attempt := 0; attempt < sampleAttempts; attempt++ {
+			var err error
+			if item, err = g.sample(sub, depth+1); err != nil {
+				return nil, err
+			}
+			if !t.UniqueItems || !seen[canonicalJSON(item)] {
+				break
+			}
+		}
+		seen[canonicalJSON(item)] = true
+		items = append(items, item)
+	}
+	if t.Contains != nil {
+		item, err := g.sample(t.Contains, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) > 0 && t.MaxItems != nil && len(items) >= *t.MaxItems {
+			items[len(items)-1] = item
+		} else {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// formatSamples are samples of the registered formats.
+var formatSamples = map[string]string{
+	"date-time":             "2021-06-15T09:30:00Z",
+	"date":                  "2021-06-15",
+	"time":                  "09:30:00Z",
+	"duration":              "P1DT12H",
+	"email":                 "user@example.com",
+	"idn-email":             "user@example.com",
+	"hostname":              "example.com",
+	"idn-hostname":          "example.com",
+	"ipv4":                  "192.0.2.1",
+	"ipv6":                  "2001:db8::1",
+	"uri":                   "https://example.com/",
+	"uri-reference":         "/example",
+	"iri":                   "https://example.com/",
+	"iri-reference":         "/example",
+	"json-pointer":          "/example/0",
+	"relative-json-pointer": "0/example",
+	"regex":                 "^[a-z]+$",
+}
+
+const sampleLetters = "abcdefghijklmnopqrstuvwxyz"
+
+func (g *sampler) sampleString(t *Type) (interface{}, error) {
+	if t.Pattern != "" {
+		return g.sampleRegexp(t.Pattern, t.MinLength, maxCount(t.MaxLength))
+	}
+	if t.Format == "uuid" {
+		return fmt.Sprintf("%08x-%04x-4%03x-%04x-%012x", g.rand.Uint32(), g.rand.Intn(1<<16), g.rand.Intn(1<<12),
+			0x8000|g.rand.Intn(1<<14), g.rand.Int63n(1<<48)), nil
+	}
+	if sample, ok := formatSamples[t.Format]; ok {
+		return sample, nil
+	}
+	min, max := t.MinLength, maxCount(t.MaxLength)
+	if min == 0 && max < 0 {
+		min, max = 3, 10
+	}
+	b := &strings.Builder{}
+	for n := g.sampleLength(min, max); n > 0; n-- {
+		b.WriteByte(sampleLetters[g.rand.Intn(len(sampleLetters))])
+	}
+	return b.String(), nil
+}
+
+// sampleLength returns a length between min and max, or up to 8 more than
+// min if max is -1. If max is less than min, no length is valid, and min is
+// returned for validSample to reject.
+func (g *sampler) sampleLength(min, max int) int {
+	if max < 0 {
+		max = min + 8
+	}
+	if max <= min {
+		return min
+	}
+	return min + g.rand.Intn(max-min+1)
+}
+
+// sampleNumber returns a number within the bounds of t, and a multiple of its
+// multipleOf.
+func (g *sampler) sampleNumber(t *Type, integer bool) interface{} {
+	lo, loExclusive, hasLo := t.minimum()
+	hi, hiExclusive, hasHi := t.maximum()
+	switch {
+	case !hasLo && !hasHi:
+		lo, hi = 0, 100
+	case !hasHi:
+		hi = lo + 100
+	case !hasLo:
+		lo = hi - 100
+	}
+	// Numbers are multiples of a step: 1 for integers, and otherwise the power
+	// of ten closest to a ten-thousandth of the width of the bounds.
+	step := 1.0
+	if !integer && hi > lo {
+		step = math.Pow10(int(math.Floor(math.Log10(hi-lo))) - 4)
+	}
+	if m, ok := new(big.Rat).SetString(t.MultipleOf.String()); ok && m.Sign() > 0 {
+		if integer {
+			// The smallest integer multiple of p/q in lowest terms is p.
+			step, _ = new(big.Rat).SetInt(m.Num()).Float64()
+		} else {
+			step, _ = m.Float64()
+		}
+	}
+	first, last := math.Ceil(lo/step), math.Floor(hi/step)
+	if loExclusive && first*step <= lo {
+		first++
+	}
+	if hiExclusive && last*step >= hi {
+		last--
+	}
+	n := first
+	if last > first {
+		n += float64(g.rand.Int63n(int64(math.Min(last-first, 1<<30)) + 1))
+	}
+	// The number is written with the decimals of the step, so that it is an
+	// exact multiple of it.
+	decimals := 0
+	if s := strconv.FormatFloat(step, 'f', -1, 64); strings.Contains(s, ".") {
+		decimals = len(s) - strings.IndexByte(s, '.') - 1
+	}
+	return json.Number(strconv.FormatFloat(n*step, 'f', decimals, 64))
+}
+
+// sampleRegexp returns a string matching pattern, of a length between min and
+// max, or of any length if max is -1, if the pattern allows one.
+func (g *sampler) sampleRegexp(pattern string, min, max int) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("jsonschema: invalid pattern %q: %s", pattern, err)
+	}
+	re = re.Simplify()
+	reMin, reMax := regexpLength(re)
+	if min < reMin {
+		min = reMin
+	}
+	if max < 0 || (reMax >= 0 && reMax < max) {
+		max = reMax
+	}
+	// Unbounded repeats are sampled a few times each, and more if the
+	// lengths close to min cannot be matched.
+	target := max
+	if max < 0 {
+		target = min + 3*regexpRepeats(re)
+	}
+	b := &strings.Builder{}
+	for attempt := 0; attempt < sampleAttempts; attempt++ {
+		b.Reset()
+		g.writeRegexp(b, re, g.sampleLength(min, target))
+		if n := utf8.RuneCountInString(b.String()); n >= min && (max < 0 || n <= max) {
+			break
+		}
+		if max < 0 {
+			target += 8
+		}
+	}
+	return b.String(), nil
+}
+
+// writeRegexp writes a string matching re, of length n in runes if re
+// matches strings of that length, or else as close to it as re allows.
+func (g *sampler) writeRegexp(b *strings.Builder, re *syntax.Regexp, n int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(g.sampleRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(sampleLetters[g.rand.Intn(len(sampleLetters))])
+	case syntax.OpCapture:
+		g.writeRegexp(b, re.Sub[0], n)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatBounds(re)
+		subMin, subMax := regexpLength(re.Sub[0])
+		// The counts of repeats whose lengths can add up to n, or else the
+		// nearest count.
+		var counts []int
+		limit := max
+		if limit < 0 || limit > min+n {
+			limit = min + n
+		}
+		for c := min; c <= limit; c++ {
+			if c*subMin <= n && (subMax < 0 || c*subMax >= n) {
+				counts = append(counts, c)
+			}
+		}
+		count := min
+		if len(counts) > 0 {
+			count = counts[g.rand.Intn(len(counts))]
+		} else if min*subMin < n {
+			count = limit
+		}
+		lengths := make([][2]int, count)
+		for i := range lengths {
+			lengths[i] = [2]int{subMin, subMax}
+		}
+		for _, length := range g.splitLength(n, lengths) {
+			g.writeRegexp(b, re.Sub[0], length)
+		}
+	case syntax.OpConcat:
+		lengths := make([][2]int, len(re.Sub))
+		for i, sub := range re.Sub {
+			lengths[i][0], lengths[i][1] = regexpLength(sub)
+		}
+		for i, length := range g.splitLength(n, lengths) {
+			g.writeRegexp(b, re.Sub[i], length)
+		}
+	case syntax.OpAlternate:
+		var fits []*syntax.Regexp
+		closest, distance := re.Sub[0], -1
+		for _, sub := range re.Sub {
+			min, max := regexpLength(sub)
+			d := 0
+			if n < min {
+				d = min - n
+			} else if max >= 0 && n > max {
+				d = n - max
+			}
+			if d == 0 {
+				fits = append(fits, sub)
+			} else if distance < 0 || d < distance {
+				closest, distance = sub, d
+			}
+		}
+		if len(fits) > 0 {
+			closest = fits[g.rand.Intn(len(fits))]
+		}
+		g.writeRegexp(b, closest, n)
+	}
+}
+
+// splitLength splits the length n into lengths within the bounds of each part,
+// given as a minimum and a maximum that is -1 if unbounded, at random.
+func (g *sampler) splitLength(n int, bounds [][2]int) []int {
+	lengths := make([]int, len(bounds))
+	var growable []int
+	for i, bound := range bounds {
+		lengths[i] = bound[0]
+		n -= bound[0]
+		if bound[1] < 0 || bound[1] > bound[0] {
+			growable = append(growable, i)
+		}
+	}
+	for ; n > 0 && len(growable) > 0; n-- {
+		j := g.rand.Intn(len(growable))
+		i := growable[j]
+		lengths[i]++
+		if bounds[i][1] >= 0 && lengths[i] == bounds[i][1] {
+			growable = append(growable[:j], growable[j+1:]...)
+		}
+	}
+	return lengths
+}
+
+// regexpLength returns the minimum and maximum lengths in runes of the
+// strings that re matches, with a maximum of -1 if it is unbounded.
+func regexpLength(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune), len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1, 1
+	case syntax.OpCapture:
+		return regexpLength(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatBounds(re)
+		subMin, subMax := regexpLength(re.Sub[0])
+		switch {
+		case max == 0 || subMax == 0:
+			return min * subMin, 0
+		case max < 0 || subMax < 0:
+			return min * subMin, -1
+		}
+		return min * subMin, max * subMax
+	case syntax.OpConcat:
+		min, max := 0, 0
+		for _, sub := range re.Sub {
+			subMin, subMax := regexpLength(sub)
+			min += subMin
+			if max >= 0 && subMax >= 0 {
+				max += subMax
+			} else {
+				max = -1
+			}
+		}
+		return min, max
+	case syntax.OpAlternate:
+		min, max := regexpLength(re.Sub[0])
+		for _, sub := range re.Sub[1:] {
+			subMin, subMax := regexpLength(sub)
+			if subMin < min {
+				min = subMin
+			}
+			if max >= 0 && (subMax < 0 || subMax > max) {
+				max = subMax
+			}
+		}
+		return min, max
+	}
+	return 0, 0
+}
+
+// regexpRepeats returns the number of unbounded repeats in re.
+func regexpRepeats(re *syntax.Regexp) int {
+	n := 0
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		if _, max := repeatBounds(re); max < 0 {
+			n++
+		}
+	}
+	for _, sub := range re.Sub {
+		n += regexpRepeats(sub)
+	}
+	return n
+}
+
+// repeatBounds returns the minimum and maximum counts of a repeat, with a
+// maximum of -1 if it is unbounded.
+func repeatBounds(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		return 1, -1
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	return re.Min, re.Max
+}
+
+// sampleRune returns a rune of a character class, given as pairs of bounds,
+// preferring printable ASCII characters.
+func (g *sampler) sampleRune(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1] && r <= '~'; r++ {
+			if r >= ' ' {
+				printable = append(printable, r)
+			}
+		}
+	}
+	if len(printable) > 0 {
+		return printable[g.rand.Intn(len(printable))]
+	}
+	if len(ranges) < 2 {
+		return 'a'
+	}
+	return ranges[2*g.rand.Intn(len(ranges)/2)]
+}
+
+// mergeSamples merges a sample of a subschema into a sample of its parent:
+// the properties of objects are added, and other values replace a null.
+func mergeSamples(value, sub interface{}) interface{} {
+	if value == nil {
+		return sub
+	}
+	object, ok := value.(map[string]interface{})
+	subObject, subOK := sub.(map[string]interface{})
+	if ok && subOK {
+		for key, item := range subObject {
+			if _, exists := object[key]; !exists {
+				object[key] = item
+			}
+		}
+	}
+	return value
+}
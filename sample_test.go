@@ -0,0 +1,119 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Order struct {
+	ID       string      `json:"id" jsonschema:"format=uuid"`
+	Customer string      `json:"customer" jsonschema:"minLength=2,maxLength=8"`
+	Email    string      `json:"email,omitempty" jsonschema:"format=email"`
+	Status   string      `json:"status" jsonschema:"enum=open,enum=shipped"`
+	Country  string      `json:"country" jsonschema:"example=NL,example=DE"`
+	Code     string      `json:"code" jsonschema:"pattern=^[A-Z]{3}-[0-9]{4}$"`
+	Quantity int         `json:"quantity" jsonschema:"minimum=1,maximum=5"`
+	Discount float64     `json:"discount,omitempty" jsonschema:"minimum=0,exclusiveMaximum=1"`
+	Lines    []OrderLine `json:"lines" jsonschema:"minItems=1"`
+	Parent   *Order      `json:"parent,omitempty"`
+}
+
+type OrderLine struct {
+	SKU   string `json:"sku" jsonschema:"default=SKU-1"`
+	Price int    `json:"price" jsonschema:"multipleOf=5,minimum=10,maximum=100"`
+}
+
+func TestSampleGenerator(t *testing.T) {
+	s := Reflect(&Order{})
+	g := &SampleGenerator{Seed: 1}
+	sample, err := g.Sample(s)
+	require.NoError(t, err)
+	require.NoError(t, s.Validate(sample))
+
+	order := sample.(map[string]interface{})
+	require.Contains(t, []interface{}{"open", "shipped"}, order["status"])
+	require.Contains(t, []interface{}{"NL", "DE"}, order["country"])
+	require.Regexp(t, `^[A-Z]{3}-[0-9]{4}$`, order["code"])
+	require.Equal(t, "SKU-1", order["lines"].([]interface{})[0].(map[string]interface{})["sku"])
+
+	// The same seed yields the same sample.
+	first, err := g.Generate(s)
+	require.NoError(t, err)
+	second, err := (&SampleGenerator{Seed: 1}).Generate(s)
+	require.NoError(t, err)
+	require.JSONEq(t, string(first), string(second))
+
+	for seed := int64(0); seed < 50; seed++ {
+		sample, err := (&SampleGenerator{Seed: seed, MaxDepth: 2}).Sample(s)
+		require.NoError(t, err, "seed %d", seed)
+		require.NoError(t, s.Validate(sample), "seed %d", seed)
+	}
+}
+
+func TestSampleGeneratorSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"untyped bounds", `{"minimum": 3, "exclusiveMaximum": 4, "multipleOf": 0.25}`},
+		{"integer", `{"type": "integer", "exclusiveMinimum": 10, "maximum": 11}`},
+		{"narrow bounds", `{"type": "number", "minimum": 0.001, "maximum": 0.002}`},
+		{"wide bounds", `{"type": "number", "minimum": -1e9, "exclusiveMaximum": 1e9}`},
+		{"integer multiple of a fraction", `{"type": "integer", "multipleOf": 0.3}`},
+		{"number multiple of a fraction", `{"type": "number", "multipleOf": 0.1, "minimum": 0.25, "maximum": 0.45}`},
+		{"pattern with minLength", `{"type": "string", "pattern": "^[a-z]+$", "minLength": 5}`},
+		{"pattern with maxLength", `{"type": "string", "pattern": "^(ab|c)*[0-9]{2,}$", "minLength": 7, "maxLength": 9}`},
+		{"pattern with alternatives", `{"type": "string", "pattern": "^(x|[a-z]{10,})$", "minLength": 2}`},
+		{"nullable", `{"type": ["null", "boolean"]}`},
+		{"const", `{"const": {"a": [1, 2]}}`},
+		{"tuple", `{"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false, "minItems": 2}`},
+		{"unique", `{"type": "array", "items": {"enum": [1, 2, 3]}, "minItems": 3, "uniqueItems": true}`},
+		{"contains", `{"type": "array", "items": {"type": "integer"}, "contains": {"const": 7}, "maxItems": 2}`},
+		{"min properties", `{"type": "object", "minProperties": 2, "additionalProperties": {"type": "boolean"}}`},
+		{"dependencies", `{"properties": {"a": {"type": "string"}, "b": {"type": "string"}}, "dependencies": {"a": ["c"]}}`},
+		{"allOf", `{"allOf": [{"required": ["a"]}, {"properties": {"b": {"type": "integer"}}, "required": ["b"]}]}`},
+		{"oneOf", `{"oneOf": [{"type": "string", "maxLength": 2}, {"type": "integer", "minimum": 100}]}`},
+		{"recursive", `{
+			"definitions": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/definitions/node"}}}}},
+			"$ref": "#/definitions/node"
+		}`},
+		{"formats", `{"type": "array", "items": [
+			{"format": "date-time"}, {"format": "ipv6"}, {"format": "hostname"}, {"format": "uri"}, {"format": "duration"}
+		], "minItems": 5, "additionalItems": false}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := mustSchema(t, test.schema)
+			for seed := int64(0); seed < 50; seed++ {
+				sample, err := (&SampleGenerator{Seed: seed}).Sample(s)
+				require.NoError(t, err, "seed %d", seed)
+				require.NoError(t, s.Validate(sample), "seed %d", seed)
+			}
+		})
+	}
+}
+
+func TestSampleGeneratorErrors(t *testing.T) {
+	_, err := (&SampleGenerator{}).Sample(mustSchema(t, `{"type": "string", "not": {"type": "string"}}`))
+	require.EqualError(t, err, "jsonschema: no sample satisfying the schema found: "+
+		"/: value must not match the not schema")
+
+	_, err = (&SampleGenerator{}).Sample(mustSchema(t, `{"type": "string", "minLength": 5, "maxLength": 2}`))
+	require.EqualError(t, err, "jsonschema: no sample satisfying the schema found: "+
+		"/: string is longer than 2 characters")
+
+	_, err = (&SampleGenerator{}).Sample(mustSchema(t, `{"type": "array", "items": {"type": "integer"}, "minItems": 3, "maxItems": 1}`))
+	require.EqualError(t, err, "jsonschema: no sample satisfying the schema found: "+
+		"/: array has more than 1 items")
+
+	sample, err := (&SampleGenerator{}).Sample(mustSchema(t, `{"type": "string", "maxLength": 0}`))
+	require.NoError(t, err)
+	require.Equal(t, "", sample)
+
+	_, err = (&SampleGenerator{MaxDepth: 1}).Sample(mustSchema(t, `{
+		"definitions": {"node": {"required": ["next"], "properties": {"next": {"$ref": "#/definitions/node"}}}},
+		"$ref": "#/definitions/node"
+	}`))
+	require.EqualError(t, err, "jsonschema: schema requires values nested more than 33 levels deep")
+}
@@ -0,0 +1,254 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ValidateDecoder reads the next JSON value from dec and validates it against
+// s as it is read, without decoding it in full. References to other documents
+// cannot be resolved; use Resolver.ValidateDecoder for schemas that have them.
+func (s *Schema) ValidateDecoder(dec *json.Decoder) error {
+	r := NewResolver(nil)
+	if err := r.AddSchema("", s); err != nil {
+		return err
+	}
+	return r.ValidateDecoder(s.Type, dec)
+}
+
+// ValidateDecoder reads the next JSON value from dec and validates it against
+// t, a schema in one of the documents of the resolver, as it is read. It can
+// be called repeatedly to validate a stream of values, such as JSON lines.
+// The decoder is switched to UseNumber. The error is ValidationErrors if the
+// value is not valid, or the error of the decoder if it cannot be read.
+//
+// Only the objects and arrays that a schema needs as a whole are kept in
+// memory: those that the schemas applied to them constrain with enum, const,
+// uniqueItems, contains, dependencies with a schema, anyOf, oneOf, not, if or
+// a registered custom keyword. Such a value is decoded in full and validated
+// as Validate does, so a oneOf at the root of a schema buffers the whole
+// document. Every other value is validated token by token, with memory
+// bounded by the depth of the document and the names of required properties.
+//
+// Failures are found in document order rather than in the order Validate
+// finds them.
+func (r *Resolver) ValidateDecoder(t *Type, dec *json.Decoder) error {
+	dec.UseNumber()
+	v := &streamValidator{validator: validator{resolver: r}, dec: dec}
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if err := v.value(tok, []schemaAt{{t, ""}}, ""); err != nil {
+		return err
+	}
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+// streamValidator validates the values read from a decoder.
+type streamValidator struct {
+	validator
+	dec *json.Decoder
+}
+
+// value validates the value starting with tok against schemas, reading the
+// rest of it from the decoder.
+func (v *streamValidator) value(tok json.Token, schemas []schemaAt, instancePath string) error {
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		for _, s := range schemas {
+			v.validate(s.t, tok, instancePath, s.path)
+		}
+		return nil
+	}
+
+	expanded, failures := v.expand(schemas, instancePath)
+	for _, s := range expanded {
+		if needsBuffer(s.t) {
+			value, err := v.read(tok)
+			if err != nil {
+				return err
+			}
+			for _, s := range schemas {
+				v.validate(s.t, value, instancePath, s.path)
+			}
+			return nil
+		}
+	}
+	v.errors = append(v.errors, failures...)
+	switch delim {
+	case '{':
+		return v.object(expanded, instancePath)
+	case '[':
+		return v.array(expanded, instancePath)
+	}
+	return fmt.Errorf("jsonschema: unexpected %s", delim)
+}
+
+// expand returns the schemas that apply to a value with schemas, through
+// references and allOf, along with the failures of false schemas and
+// unresolvable references.
+func (v *streamValidator) expand(schemas []schemaAt, instancePath string) ([]schemaAt, ValidationErrors) {
+	sub := &validator{resolver: v.resolver}
+	var expanded []schemaAt
+	var walk func(t *Type, schemaPath string)
+	walk = func(t *Type, schemaPath string) {
+		if _, ok := t.Boolean(); ok {
+			sub.validate(t, nil, instancePath, schemaPath)
+			return
+		}
+		if t.Ref != "" {
+			target, _, err := v.resolver.Resolve(v.resolver.Base(t), t.Ref)
+			if err != nil {
+				sub.fail(instancePath, schemaPath, "$ref", "%s", err)
+				return
+			}
+			walk(target, schemaPath+"/$ref")
+			return
+		}
+		expanded = append(expanded, schemaAt{t, schemaPath})
+		for i, s := range t.AllOf {
+			walk(s, schemaPath+"/allOf/"+strconv.Itoa(i))
+		}
+	}
+	for _, s := range schemas {
+		walk(s.t, s.path)
+	}
+	return expanded, sub.errors
+}
+
+// needsBuffer reports whether t has keywords that need an object or array as
+// a whole, including custom keywords.
+func needsBuffer(t *Type) bool {
+	return len(t.Enum) > 0 || t.HasConst() || t.UniqueItems || t.Contains != nil ||
+		len(t.Dependencies) > 0 || len(t.AnyOf) > 0 || len(t.OneOf) > 0 || t.Not != nil || t.If != nil ||
+		len(t.registeredKeywords()) > 0
+}
+
+// object validates an object whose opening brace has been read.
+func (v *streamValidator) object(schemas []schemaAt, instancePath string) error {
+	tracked := map[string]bool{}
+	for _, s := range schemas {
+		v.validateType(s.t, map[string]interface{}(nil), instancePath, s.path)
+		for _, name := range s.t.Required {
+			tracked[name] = true
+		}
+		for name, dependencies := range s.t.DependentRequired {
+			tracked[name] = true
+			for _, dependency := range dependencies {
+				tracked[dependency] = true
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	n := 0
+	for v.dec.More() {
+		tok, err := v.dec.Token()
+		if err != nil {
+			return err
+		}
+		name := tok.(string)
+		n++
+		if tracked[name] {
+			seen[name] = true
+		}
+		var children []schemaAt
+		for _, s := range schemas {
+			children = v.propertySchemas(s.t, name, instancePath, s.path, children)
+		}
+		if tok, err = v.dec.Token(); err != nil {
+			return err
+		}
+		if err := v.value(tok, children, instancePath+"/"+escapePointerToken(name)); err != nil {
+			return err
+		}
+	}
+	if _, err := v.dec.Token(); err != nil {
+		return err
+	}
+
+	has := func(name string) bool { return seen[name] }
+	for _, s := range schemas {
+		v.validatePropertyCount(s.t, n, has, instancePath, s.path)
+		v.validateDependentRequired(s.t, has, instancePath, s.path)
+	}
+	return nil
+}
+
+// array validates an array whose opening bracket has been read.
+func (v *streamValidator) array(schemas []schemaAt, instancePath string) error {
+	for _, s := range schemas {
+		v.validateType(s.t, []interface{}(nil), instancePath, s.path)
+	}
+
+	n := 0
+	for ; v.dec.More(); n++ {
+		var children []schemaAt
+		for _, s := range schemas {
+			if sub, subPath := itemSchema(s.t, n, s.path); sub != nil {
+				children = append(children, schemaAt{sub, subPath})
+			}
+		}
+		tok, err := v.dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := v.value(tok, children, instancePath+"/"+strconv.Itoa(n)); err != nil {
+			return err
+		}
+	}
+	if _, err := v.dec.Token(); err != nil {
+		return err
+	}
+
+	for _, s := range schemas {
+		v.validateItemCount(s.t, n, instancePath, s.path)
+	}
+	return nil
+}
+
+// read decodes the value starting with tok, reading the rest of it from the
+// decoder.
+func (v *streamValidator) read(tok json.Token) (interface{}, error) {
+	switch tok {
+	case json.Delim('{'):
+		object := map[string]interface{}{}
+		for v.dec.More() {
+			key, err := v.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if tok, err = v.dec.Token(); err != nil {
+				return nil, err
+			}
+			value, err := v.read(tok)
+			if err != nil {
+				return nil, err
+			}
+			object[key.(string)] = value
+		}
+		_, err := v.dec.Token()
+		return object, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for v.dec.More() {
+			tok, err := v.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			item, err := v.read(tok)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := v.dec.Token()
+		return items, err
+	}
+	return tok, nil
+}
@@ -0,0 +1,82 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDecoder(t *testing.T) {
+	schema := `{
+		"definitions": {
+			"line": {
+				"type": "object",
+				"required": ["sku", "quantity"],
+				"properties": {
+					"sku": {"type": "string", "pattern": "^[A-Z]+$"},
+					"quantity": {"type": "integer", "minimum": 1}
+				},
+				"additionalProperties": false
+			}
+		},
+		"type": "object",
+		"required": ["id"],
+		"properties": {
+			"id": {"type": "string", "minLength": 2},
+			"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}, "maxItems": 2},
+			"tags": {"type": "array", "uniqueItems": true},
+			"status": {"oneOf": [{"const": "open"}, {"type": "object", "required": ["closed"]}]},
+			"point": {"items": [{"type": "number"}, {"type": "number"}], "additionalItems": false}
+		},
+		"patternProperties": {"^x-": {"type": "string"}},
+		"allOf": [{"maxProperties": 5}],
+		"dependencies": {"point": ["lines"]}
+	}`
+	tests := []string{
+		`{"id": "o1", "lines": [{"sku": "A", "quantity": 1}], "tags": ["a", "b"], "status": "open"}`,
+		`{"lines": [{"sku": "a", "quantity": 0, "extra": true}, {"sku": "B"}, {"sku": "C", "quantity": 1}]}`,
+		`{"id": "x", "tags": ["a", "a"], "status": {"open": true}, "x-a": 1, "point": [1, 2, 3]}`,
+		`{"id": "o1", "a": 1, "b": 2, "c": 3, "d": 4, "e": 5}`,
+		`{"id": "o1", "lines": {"sku": "A"}}`,
+		`[1, 2]`,
+		`"o1"`,
+	}
+	s := mustSchema(t, schema)
+	for _, test := range tests {
+		t.Run(test, func(t *testing.T) {
+			expected := s.ValidateJSON([]byte(test))
+			actual := s.ValidateDecoder(json.NewDecoder(strings.NewReader(test)))
+			require.Equal(t, sortedErrors(expected), sortedErrors(actual))
+		})
+	}
+}
+
+func TestValidateDecoderStream(t *testing.T) {
+	s := mustSchema(t, `{"type": "object", "properties": {"n": {"type": "integer", "maximum": 10}}}`)
+	dec := json.NewDecoder(strings.NewReader(`{"n": 1}
+{"n": 11}
+{"n": 12.5}
+`))
+	require.NoError(t, s.ValidateDecoder(dec))
+	require.EqualError(t, s.ValidateDecoder(dec), "/n: 11 is greater than 10")
+	require.EqualError(t, s.ValidateDecoder(dec), "/n: expected integer, but got number\n/n: 12.5 is greater than 10")
+	require.Equal(t, io.EOF, s.ValidateDecoder(dec))
+
+	err := s.ValidateDecoder(json.NewDecoder(strings.NewReader(`{"n": [1,}`)))
+	require.IsType(t, &json.SyntaxError{}, err)
+}
+
+// sortedErrors returns the messages of the validation errors in err, sorted.
+func sortedErrors(err error) []string {
+	var messages []string
+	errs, _ := err.(ValidationErrors)
+	for _, e := range errs {
+		messages = append(messages, e.SchemaPath+" "+e.Error())
+	}
+	sort.Strings(messages)
+	return messages
+}
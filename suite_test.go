@@ -0,0 +1,168 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// suiteDir holds a copy of the official JSON-Schema-Test-Suite.
+const suiteDir = "fixtures/JSON-Schema-Test-Suite"
+
+// suiteSkips lists the cases of the suite that are known to fail, as
+// "draft/file/case", or "draft/file/case/test" for a single test, with the
+// reason. A draft of "*" matches every draft.
+var suiteSkips = map[string]string{
+	"*/definitions.json/valid definition":              "the meta-schemas are not vendored",
+	"*/definitions.json/invalid definition":            "the meta-schemas are not vendored",
+	"*/ref.json/remote ref, containing refs itself":    "the meta-schemas are not vendored",
+	"*/optional/bignum.json":                           "numbers are compared as float64",
+	"*/optional/content.json":                          "contentMediaType and contentEncoding are annotations",
+	"*/optional/ecmascript-regex.json":                 "patterns are Go regular expressions",
+	"*/optional/zeroTerminatedFloats.json":             "1.0 is an integer",
+	"*/optional/format/uri-template.json":              "uri-template is not a registered format",
+	"draft6/optional/format.json/format: uri-template": "uri-template is not a registered format",
+	"draft7/optional/format/idn-hostname.json/validation of internationalized host names/contains illegal char U+302E Hangul single dot tone mark": "the IDNA2008 code point tables are not implemented",
+}
+
+type suiteCase struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Tests       []struct {
+		Description string          `json:"description"`
+		Data        json.RawMessage `json:"data"`
+		Valid       bool            `json:"valid"`
+	} `json:"tests"`
+}
+
+// suiteResult counts the tests of a keyword.
+type suiteResult struct {
+	passed, failed, skipped int
+}
+
+func TestSuite(t *testing.T) {
+	remotes := MemoryLoader{}
+	err := filepath.Walk(filepath.Join(suiteDir, "remotes"), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(filepath.Join(suiteDir, "remotes"), path)
+		remotes["http://localhost:1234/"+filepath.ToSlash(rel)] = readSuiteSchema(t, path)
+		return nil
+	})
+	require.NoError(t, err)
+
+	results := map[string]*suiteResult{}
+	used := map[string]bool{}
+	for _, draft := range []string{"draft4", "draft6", "draft7"} {
+		dir := filepath.Join(suiteDir, "tests", draft)
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			file, _ := filepath.Rel(dir, path)
+			file = filepath.ToSlash(file)
+			keyword := draft + "/" + strings.TrimSuffix(file, ".json")
+			if results[keyword] == nil {
+				results[keyword] = &suiteResult{}
+			}
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			var cases []suiteCase
+			require.NoError(t, json.Unmarshal(data, &cases))
+			for _, c := range cases {
+				runSuiteCase(t, remotes, draft+"/"+file, c, results[keyword], used)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	keywords := make([]string, 0, len(results))
+	for keyword := range results {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	total := suiteResult{}
+	for _, keyword := range keywords {
+		r := results[keyword]
+		t.Logf("%-42s %4d passed %4d failed %4d skipped", keyword, r.passed, r.failed, r.skipped)
+		total.passed += r.passed
+		total.failed += r.failed
+		total.skipped += r.skipped
+	}
+	t.Logf("%-42s %4d passed %4d failed %4d skipped", "total", total.passed, total.failed, total.skipped)
+
+	for key := range suiteSkips {
+		if !used[key] {
+			t.Errorf("skip %q matches no test", key)
+		}
+	}
+}
+
+// runSuiteCase runs the tests of a case of file against both Validate and a
+// compiled Validator, counting them in result and the skips matched in used.
+func runSuiteCase(t *testing.T, remotes Loader, file string, c suiteCase, result *suiteResult, used map[string]bool) {
+	t.Run(file+"/"+c.Description, func(t *testing.T) {
+		s := &Schema{}
+		require.NoError(t, json.Unmarshal(c.Schema, s))
+		r := NewResolver(remotes)
+		require.NoError(t, r.AddSchema("", s))
+		v, compileErr := r.Compile(s.Type)
+
+		for _, test := range c.Tests {
+			if key, ok := suiteSkip(file, c.Description, test.Description); ok {
+				used[key] = true
+				result.skipped++
+				t.Logf("skipped %q: %s", test.Description, suiteSkips[key])
+				continue
+			}
+			var instance interface{}
+			require.NoError(t, decodeJSON(test.Data, &instance))
+			err := r.Validate(s.Type, instance)
+			ok := (err == nil) == test.Valid
+			if compileErr != nil {
+				t.Errorf("%s: %s", test.Description, compileErr)
+				ok = false
+			} else if compiled := v.Validate(instance); (compiled == nil) != test.Valid {
+				t.Errorf("%s: compiled validator: valid is %v, expected %v: %v", test.Description, compiled == nil, test.Valid, compiled)
+				ok = false
+			}
+			if !ok {
+				t.Errorf("%s: valid is %v, expected %v: %v", test.Description, err == nil, test.Valid, err)
+				result.failed++
+			} else {
+				result.passed++
+			}
+		}
+	})
+}
+
+// suiteSkip returns the key of the skip that matches a test, if any.
+func suiteSkip(file, description, test string) (string, bool) {
+	draft := file[:strings.IndexByte(file, '/')]
+	rest := file[len(draft):]
+	for _, key := range []string{
+		file, file + "/" + description, file + "/" + description + "/" + test,
+		"*" + rest, "*" + rest + "/" + description, "*" + rest + "/" + description + "/" + test,
+	} {
+		if _, ok := suiteSkips[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func readSuiteSchema(t *testing.T, path string) *Schema {
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	s := &Schema{}
+	require.NoError(t, json.Unmarshal(data, s))
+	return s
+}
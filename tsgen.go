@@ -0,0 +1,308 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TypeScriptGenerator generates TypeScript declarations for the types
+// described by a schema.
+//
+// Each definition becomes an exported interface, for an object schema with
+// properties that may not be null, or an exported type alias. Properties that
+// are not required are optional, enums become unions of literal types, maps
+// described by patternProperties or additionalProperties become
+// Record<string, T>, oneOf and anyOf become unions and allOf an intersection.
+// Descriptions become JSDoc comments. Validation keywords such as minLength
+// have no TypeScript counterpart and are left out.
+type TypeScriptGenerator struct {
+	// RootName is the name of the type generated for the root schema when it
+	// is not a reference to a definition. It defaults to the title of the
+	// schema, or "Root".
+	RootName string
+}
+
+// Generate returns TypeScript source code for the types described by s.
+func (g *TypeScriptGenerator) Generate(s *Schema) ([]byte, error) {
+	gen := &tsGenerator{definitions: map[string]string{}}
+	definitions := mergedDefinitions(s)
+	used := map[string]bool{}
+	unique := func(name string) string {
+		candidate := name
+		for i := 2; used[candidate]; i++ {
+			candidate = fmt.Sprintf("%s%d", name, i)
+		}
+		used[candidate] = true
+		return candidate
+	}
+	names := sortedKeys(definitions)
+	for _, name := range names {
+		tsName := goIdentifier(name)
+		if tsName == "" {
+			tsName = "Definition"
+		}
+		gen.definitions[name] = unique(tsName)
+	}
+
+	out := &bytes.Buffer{}
+	if s.Type != nil {
+		if _, ok := definitionRef(s.Type.Ref); !ok {
+			rootName := g.RootName
+			if rootName == "" {
+				rootName = goIdentifier(s.Type.Title)
+			}
+			if rootName == "" {
+				rootName = "Root"
+			}
+			gen.declare(out, unique(rootName), s.Type)
+		}
+	}
+	for _, name := range names {
+		gen.declare(out, gen.definitions[name], definitions[name])
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+type tsGenerator struct {
+	definitions map[string]string // TypeScript type names by definition name
+}
+
+// declare writes the declaration of the named type for t.
+func (g *tsGenerator) declare(out *bytes.Buffer, name string, t *Type) {
+	writeJSDoc(out, t.Description, "")
+	if g.isInterface(t) {
+		fmt.Fprintf(out, "export interface %s %s\n\n", name, g.object(t, ""))
+		return
+	}
+	fmt.Fprintf(out, "export type %s = %s;\n\n", name, g.tsType(t, ""))
+}
+
+// isInterface reports whether t can be declared as an interface, which
+// cannot be null.
+func (g *tsGenerator) isInterface(t *Type) bool {
+	return isStruct(t) && t.Ref == "" && len(t.Enum) == 0 && !t.HasConst() &&
+		len(t.AllOf) == 0 && len(t.AnyOf) == 0 && len(t.OneOf) == 0 &&
+		len(nonNullTypes(t)) == len(t.typeList())
+}
+
+// object returns an object type literal for the properties of t.
+func (g *tsGenerator) object(t *Type, indent string) string {
+	b := &bytes.Buffer{}
+	b.WriteString("{\n")
+	for _, property := range t.Properties.Keys() {
+		sub, _ := t.Properties.Get(property)
+		writeJSDoc(b, sub.Description, indent+"  ")
+		optional := "?"
+		if containsString(t.Required, property) {
+			optional = ""
+		}
+		fmt.Fprintf(b, "%s  %s%s: %s;\n", indent, tsPropertyName(property), optional, g.tsType(sub, indent+"  "))
+	}
+	if values := g.values(t, indent+"  "); values != "" {
+		// The index signature covers the properties too.
+		types := []string{values}
+		for _, property := range t.Properties.Keys() {
+			sub, _ := t.Properties.Get(property)
+			types = append(types, g.tsType(sub, indent+"  "))
+		}
+		fmt.Fprintf(b, "%s  [key: string]: %s;\n", indent, tsUnion(types))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// values returns the type of the values of a map described by the
+// patternProperties or additionalProperties of t, if any.
+func (g *tsGenerator) values(t *Type, indent string) string {
+	var types []string
+	for _, pattern := range sortedKeys(t.PatternProperties) {
+		types = append(types, g.tsType(t.PatternProperties[pattern], indent))
+	}
+	if t.AdditionalProperties != nil {
+		if allowed, ok := t.AdditionalProperties.Boolean(); !ok {
+			types = append(types, g.tsType(t.AdditionalProperties, indent))
+		} else if allowed && len(types) > 0 {
+			types = append(types, "unknown")
+		}
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	return tsUnion(types)
+}
+
+// tsType returns the TypeScript type for t.
+func (g *tsGenerator) tsType(t *Type, indent string) string {
+	if allowed, ok := t.Boolean(); ok {
+		if allowed {
+			return "unknown"
+		}
+		return "never"
+	}
+	if t.Ref != "" {
+		if name, ok := definitionRef(t.Ref); ok {
+			if tsName, ok := g.definitions[name]; ok {
+				return tsName
+			}
+		}
+		return "unknown"
+	}
+	if len(t.Enum) > 0 {
+		literals := make([]string, len(t.Enum))
+		for i, value := range t.Enum {
+			literals[i] = tsLiteral(value)
+		}
+		return tsUnion(literals)
+	}
+	if t.HasConst() {
+		return tsLiteral(t.Const)
+	}
+	if len(t.OneOf) > 0 || len(t.AnyOf) > 0 {
+		var types []string
+		for _, sub := range append(append([]*Type{}, t.OneOf...), t.AnyOf...) {
+			types = append(types, g.tsType(sub, indent))
+		}
+		return tsUnion(types)
+	}
+	if len(t.AllOf) > 0 {
+		var types []string
+		for _, sub := range t.AllOf {
+			types = append(types, tsGroup(g.tsType(sub, indent)))
+		}
+		return strings.Join(types, " & ")
+	}
+
+	typeList := t.typeList()
+	if len(typeList) == 0 {
+		if t.Properties.Len() > 0 || len(t.PatternProperties) > 0 {
+			typeList = []string{"object"}
+		} else {
+			return "unknown"
+		}
+	}
+	var types []string
+	for _, typ := range typeList {
+		switch typ {
+		case "string", "boolean", "null":
+			types = append(types, typ)
+		case "integer", "number":
+			types = append(types, "number")
+		case "array":
+			types = append(types, g.array(t, indent))
+		case "object":
+			types = append(types, g.objectType(t, indent))
+		default:
+			types = append(types, "unknown")
+		}
+	}
+	return tsUnion(types)
+}
+
+// array returns the TypeScript type of an array schema.
+func (g *tsGenerator) array(t *Type, indent string) string {
+	if t.TupleItems != nil {
+		items := make([]string, len(t.TupleItems))
+		for i, item := range t.TupleItems {
+			items[i] = g.tsType(item, indent)
+		}
+		if t.AdditionalItems == nil {
+			items = append(items, "...unknown[]")
+		} else if allowed, ok := t.AdditionalItems.Boolean(); !ok {
+			items = append(items, "..."+tsGroup(g.tsType(t.AdditionalItems, indent))+"[]")
+		} else if allowed {
+			items = append(items, "...unknown[]")
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	if t.Items != nil {
+		return tsGroup(g.tsType(t.Items, indent)) + "[]"
+	}
+	return "unknown[]"
+}
+
+// objectType returns the TypeScript type of an object schema.
+func (g *tsGenerator) objectType(t *Type, indent string) string {
+	if t.Properties.Len() > 0 {
+		return g.object(t, indent)
+	}
+	if values := g.values(t, indent); values != "" {
+		return "Record<string, " + values + ">"
+	}
+	return "Record<string, unknown>"
+}
+
+// tsUnion returns the union of types, without duplicates. A union with
+// unknown is unknown.
+func tsUnion(types []string) string {
+	seen := map[string]bool{}
+	var union []string
+	for _, typ := range types {
+		if typ == "unknown" {
+			return typ
+		}
+		if !seen[typ] {
+			seen[typ] = true
+			union = append(union, typ)
+		}
+	}
+	return strings.Join(union, " | ")
+}
+
+// tsGroup parenthesizes a union or intersection type, so that it can be used
+// as an array element or intersection member.
+func tsGroup(typ string) string {
+	depth := 0
+	for i, r := range typ {
+		switch r {
+		case '{', '[', '(', '<':
+			depth++
+		case '}', ']', ')', '>':
+			depth--
+		case '|', '&':
+			if depth == 0 && i > 0 && typ[i-1] == ' ' {
+				return "(" + typ + ")"
+			}
+		}
+	}
+	return typ
+}
+
+// tsLiteral returns the literal type of a JSON value. The JSON encoding of
+// arrays and objects reads as tuple and object types of literals.
+func tsLiteral(value interface{}) string {
+	return canonicalJSON(value)
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsPropertyName returns name as a TypeScript property name, quoted if it is
+// not an identifier.
+func tsPropertyName(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	quoted, _ := json.Marshal(name)
+	return string(quoted)
+}
+
+// writeJSDoc writes text as a JSDoc comment with the given indent.
+func writeJSDoc(w *bytes.Buffer, text, indent string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	text = strings.ReplaceAll(text, "*/", "*\\/")
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(w, "%s/** %s */\n", indent, text)
+		return
+	}
+	fmt.Fprintf(w, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(w, "%s * %s\n", indent, strings.TrimRight(line, " \t\r"))
+	}
+	fmt.Fprintf(w, "%s */\n", indent)
+}
@@ -0,0 +1,82 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeScriptGenerator(t *testing.T) {
+	data, err := ioutil.ReadFile("fixtures/gogen.json")
+	require.NoError(t, err)
+	order := &Schema{}
+	require.NoError(t, json.Unmarshal(data, order))
+	data, err = ioutil.ReadFile("fixtures/tsgen.json")
+	require.NoError(t, err)
+	event := &Schema{}
+	require.NoError(t, json.Unmarshal(data, event))
+
+	tests := []struct {
+		schema  *Schema
+		fixture string
+	}{
+		{Reflect(&TestUser{}), "fixtures/test_user.ts.golden"},
+		{order, "fixtures/order.ts.golden"},
+		{event, "fixtures/tsgen.ts.golden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			actual, err := (&TypeScriptGenerator{}).Generate(tt.schema)
+			require.NoError(t, err)
+			expected, err := ioutil.ReadFile(tt.fixture)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(actual)+"\n")
+		})
+	}
+}
+
+func TestTypeScriptDefinitionNames(t *testing.T) {
+	s := mustSchema(t, `{
+		"definitions": {"-": {"type": "string"}, "_": {"type": "integer"}},
+		"properties": {"a": {"$ref": "#/definitions/-"}, "b": {"$ref": "#/definitions/_"}}
+	}`)
+	actual, err := (&TypeScriptGenerator{}).Generate(s)
+	require.NoError(t, err)
+	require.Equal(t, `export interface Root {
+  a?: Definition;
+  b?: Definition2;
+}
+
+export type Definition = string;
+
+export type Definition2 = number;
+`, string(actual))
+}
+
+func TestTypeScriptTypes(t *testing.T) {
+	tests := map[string]string{
+		`true`:                         `unknown`,
+		`false`:                        `never`,
+		`{"type": ["string", "null"]}`: `string | null`,
+		`{"type": "array", "items": {"type": ["string", "integer"]}}`:                `(string | number)[]`,
+		`{"type": "array", "items": [{"type": "string"}], "additionalItems": false}`: `[string]`,
+		`{"enum": ["a", 1, null]}`:      `"a" | 1 | null`,
+		`{"const": {"kind": "circle"}}`: `{"kind":"circle"}`,
+		`{"allOf": [{"$ref": "#/definitions/A"}, {"anyOf": [{"type": "string"}, {"type": "number"}]}]}`: `A & (string | number)`,
+		`{"type": "object", "patternProperties": {"^x-": {"type": "integer"}}}`:                         `Record<string, number>`,
+		`{"type": "object"}`: `Record<string, unknown>`,
+		`{"type": "object", "patternProperties": {"^x-": {"type": "integer"}}, "additionalProperties": {"type": "string"}}`:  `Record<string, number | string>`,
+		`{"type": "object", "patternProperties": {"^x-": {"type": "integer"}}, "additionalProperties": true}`:                `Record<string, unknown>`,
+		`{"type": "array", "items": [{"type": "string"}], "additionalItems": {"type": ["integer", "null"]}}`:                 `[string, ...(number | null)[]]`,
+		`{"type": "array", "items": [{"type": "string"}]}`:                                                                   `[string, ...unknown[]]`,
+		`{"type": ["object", "null"], "properties": {"a": {"type": "string"}}, "additionalProperties": {"type": "integer"}}`: "{\n  a?: string;\n  [key: string]: number | string;\n} | null",
+	}
+	g := &tsGenerator{definitions: map[string]string{"A": "A"}}
+	for schema, expected := range tests {
+		typ := &Type{}
+		require.NoError(t, json.Unmarshal([]byte(schema), typ))
+		require.Equal(t, expected, g.tsType(typ, ""), schema)
+	}
+}
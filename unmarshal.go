@@ -0,0 +1,73 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+var defaultReflector = &Reflector{Cache: &ValidatorCache{}}
+
+// A ValidatorCache holds the validators compiled from the schemas a Reflector
+// reflects from Go types, by type. The zero value is ready to use. It is safe
+// for concurrent use.
+type ValidatorCache struct {
+	validators sync.Map // *Validator by reflect.Type
+}
+
+// Unmarshal validates the JSON document data against the schema reflected
+// from the type of v, and decodes it into v only if it is valid. The error is
+// ValidationErrors if the document is not valid.
+//
+// Schemas are reflected and compiled once per type and cached.
+func Unmarshal(data []byte, v interface{}) error {
+	return defaultReflector.Unmarshal(data, v)
+}
+
+// Unmarshal validates the JSON document data against the schema reflected
+// by r from the type of v, which must be a non-nil pointer, and decodes it
+// into v only if it is valid. The error is ValidationErrors if the document
+// is not valid.
+//
+// The schema is compiled, and cached in the Cache of r if it has one.
+func (r *Reflector) Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	validator, err := r.validator(rv.Type().Elem())
+	if err != nil {
+		return err
+	}
+	if err := validator.ValidateJSON(data); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// validator returns the validator of the schema of t, reflecting and
+// compiling it unless it is in the cache of r.
+func (r *Reflector) validator(t reflect.Type) (*Validator, error) {
+	if r.Cache != nil {
+		if v, ok := r.Cache.validators.Load(t); ok {
+			return v.(*Validator), nil
+		}
+	}
+	s := r.ReflectFromType(t)
+	resolver := NewResolver(nil)
+	if r.Resolver != nil {
+		resolver.StrictFormats = r.Resolver.StrictFormats
+	}
+	if err := resolver.AddSchema("", s); err != nil {
+		return nil, err
+	}
+	v, err := resolver.Compile(s.Type)
+	if err != nil {
+		return nil, err
+	}
+	if r.Cache != nil {
+		cached, _ := r.Cache.validators.LoadOrStore(t, v)
+		v = cached.(*Validator)
+	}
+	return v, nil
+}
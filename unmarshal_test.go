@@ -0,0 +1,54 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type Signup struct {
+	Email    string   `json:"email" jsonschema:"format=email"`
+	Password string   `json:"password" jsonschema:"minLength=8"`
+	Age      int      `json:"age,omitempty" jsonschema:"minimum=13"`
+	Tags     []string `json:"tags,omitempty" jsonschema:"required,maxItems=2"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	var signup Signup
+	require.NoError(t, Unmarshal([]byte(`{"email": "a@example.com", "password": "12345678", "age": 30}`), &signup))
+	require.Equal(t, Signup{Email: "a@example.com", Password: "12345678", Age: 30}, signup)
+
+	signup = Signup{}
+	err := Unmarshal([]byte(`{"email": "nobody", "password": "1", "extra": true}`), &signup)
+	require.Equal(t, ValidationErrors{
+		{InstancePath: "/email", SchemaPath: "/$ref/properties/email/format", Keyword: "format", Message: `"nobody" is not a valid email`},
+		{InstancePath: "/extra", SchemaPath: "/$ref/additionalProperties", Keyword: "additionalProperties", Message: `property "extra" is not allowed`},
+		{InstancePath: "/password", SchemaPath: "/$ref/properties/password/minLength", Keyword: "minLength", Message: "string is shorter than 8 characters"},
+	}, err)
+	require.Equal(t, Signup{}, signup, "invalid documents are not decoded")
+
+	_, isSyntaxError := Unmarshal([]byte(`{`), &signup).(*json.SyntaxError)
+	require.True(t, isSyntaxError)
+	require.IsType(t, &json.InvalidUnmarshalError{}, Unmarshal([]byte(`{}`), signup))
+}
+
+func TestReflectorUnmarshal(t *testing.T) {
+	r := &Reflector{RequiredFromJSONSchemaTags: true, AllowAdditionalProperties: true, Cache: &ValidatorCache{}}
+	var signup Signup
+	err := r.Unmarshal([]byte(`{"extra": 1}`), &signup)
+	require.EqualError(t, err, `/: missing required property "tags"`)
+	require.NoError(t, r.Unmarshal([]byte(`{"tags": ["a"], "extra": 1}`), &signup))
+	require.Equal(t, []string{"a"}, signup.Tags)
+
+	_, cached := r.Cache.validators.Load(reflect.TypeOf(signup))
+	require.True(t, cached)
+
+	// Copies share the cache.
+	copied := *r
+	require.NoError(t, copied.Unmarshal([]byte(`{"tags": ["b"]}`), &signup))
+	require.Equal(t, []string{"b"}, signup.Tags)
+
+	require.NoError(t, (&Reflector{}).Unmarshal([]byte(`{"email": "a@example.com", "password": "12345678"}`), &signup))
+}
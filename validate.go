@@ -0,0 +1,541 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// A ValidationError describes a keyword of a schema that a value failed.
+type ValidationError struct {
+	// InstancePath is the JSON Pointer of the value within the validated
+	// document.
+	InstancePath string `json:"instancePath"`
+	// SchemaPath is the JSON Pointer of the failed keyword within the schema,
+	// through any references that were followed to reach it.
+	SchemaPath string `json:"schemaPath"`
+	// Keyword is the failed keyword, such as "required" or "maxLength".
+	Keyword string `json:"keyword"`
+	// Message describes the failure.
+	Message string `json:"message"`
+	// GoPath is the Go expression of the value within the validated Go
+	// value, such as "Order.Lines[0].SKU". It is only set by ValidateValue.
+	GoPath string `json:"goPath,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	path := e.InstancePath
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + e.Message
+}
+
+// ValidationErrors is the error returned when a value is not valid against a
+// schema, listing every failure in the order found.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate validates instance against s. The instance is a value decoded
+// from JSON, such as by json.Unmarshal into an interface{}, with numbers as
+// float64 or json.Number. The error is ValidationErrors if the instance is not
+// valid. References to other documents cannot be resolved; use a Resolver to
+// validate against schemas that have them.
+func (s *Schema) Validate(instance interface{}) error {
+	r := NewResolver(nil)
+	if err := r.AddSchema("", s); err != nil {
+		return err
+	}
+	return r.Validate(s.Type, instance)
+}
+
+// ValidateJSON decodes the JSON document data and validates it against s.
+func (s *Schema) ValidateJSON(data []byte) error {
+	var instance interface{}
+	if err := decodeJSON(data, &instance); err != nil {
+		return err
+	}
+	return s.Validate(instance)
+}
+
+// Validate validates instance against t, a schema in one of the documents of
+// the resolver, as Schema.Validate does. References are resolved, and
+// external documents loaded, by the resolver.
+func (r *Resolver) Validate(t *Type, instance interface{}) error {
+	v := &validator{resolver: r}
+	v.validate(t, instance, "", "")
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+// validator validates an instance by walking the schema.
+// RFC draft-handrews-json-schema-validation-01, section 6
+type validator struct {
+	resolver *Resolver
+	errors   ValidationErrors
+}
+
+// fail records a failure of the keyword at schemaPath for the value at
+// instancePath.
+func (v *validator) fail(instancePath, schemaPath, keyword, format string, args ...interface{}) {
+	v.errors = append(v.errors, &ValidationError{
+		InstancePath: instancePath,
+		SchemaPath:   schemaPath + "/" + keyword,
+		Keyword:      keyword,
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+// valid reports whether instance is valid against t, without recording any
+// failures.
+func (v *validator) valid(t *Type, instance interface{}) bool {
+	sub := &validator{resolver: v.resolver}
+	sub.validate(t, instance, "", "")
+	return len(sub.errors) == 0
+}
+
+// validate validates the value at instancePath against the schema at
+// schemaPath.
+func (v *validator) validate(t *Type, instance interface{}, instancePath, schemaPath string) {
+	if allowed, ok := t.Boolean(); ok {
+		if !allowed {
+			v.errors = append(v.errors, &ValidationError{
+				InstancePath: instancePath,
+				SchemaPath:   schemaPath,
+				Keyword:      "false",
+				Message:      "no value is allowed",
+			})
+		}
+		return
+	}
+	// Up to draft-07, keywords beside a $ref are ignored.
+	if t.Ref != "" {
+		target, _, err := v.resolver.Resolve(v.resolver.Base(t), t.Ref)
+		if err != nil {
+			v.fail(instancePath, schemaPath, "$ref", "%s", err)
+			return
+		}
+		v.validate(target, instance, instancePath, schemaPath+"/$ref")
+		return
+	}
+
+	v.validateType(t, instance, instancePath, schemaPath)
+	if len(t.Enum) > 0 && !containsJSON(t.Enum, instance) {
+		values := make([]string, len(t.Enum))
+		for i, value := range t.Enum {
+			values[i] = canonicalJSON(value)
+		}
+		v.fail(instancePath, schemaPath, "enum", "value must be one of %s", strings.Join(values, ", "))
+	}
+	if t.HasConst() && !equalJSON(t.Const, instance) {
+		v.fail(instancePath, schemaPath, "const", "value must be %s", canonicalJSON(t.Const))
+	}
+
+	switch value := instance.(type) {
+	case string:
+		v.validateString(t, value, instancePath, schemaPath)
+	case []interface{}:
+		v.validateArray(t, value, instancePath, schemaPath)
+	case map[string]interface{}:
+		v.validateObject(t, value, instancePath, schemaPath)
+	default:
+		if _, ok := toFloat(instance); ok {
+			v.validateNumber(t, instance, instancePath, schemaPath)
+		}
+	}
+	v.validateCombinators(t, instance, instancePath, schemaPath)
+	for _, keyword := range t.registeredKeywords() {
+		if err := keyword.validate(keyword.value, instance); err != nil {
+			v.fail(instancePath, schemaPath, keyword.name, "%s", err)
+		}
+	}
+}
+
+// validateType checks the type keyword.
+// RFC draft-handrews-json-schema-validation-01, section 6.1.1
+func (v *validator) validateType(t *Type, instance interface{}, instancePath, schemaPath string) {
+	types := t.typeList()
+	if len(types) == 0 {
+		return
+	}
+	actual := jsonType(instance)
+	for _, typ := range types {
+		if typ == actual || (typ == "number" && actual == "integer") {
+			return
+		}
+	}
+	v.fail(instancePath, schemaPath, "type", "expected %s, but got %s", strings.Join(types, " or "), actual)
+}
+
+// validateNumber checks the numeric keywords.
+// RFC draft-handrews-json-schema-validation-01, section 6.2
+func (v *validator) validateNumber(t *Type, instance interface{}, instancePath, schemaPath string) {
+	n, _ := toFloat(instance)
+	if t.MultipleOf != "" && !isMultipleOf(instance, t.MultipleOf) {
+		v.fail(instancePath, schemaPath, "multipleOf", "%s is not a multiple of %s", formatNumber(n), t.MultipleOf)
+	}
+	if max, exclusive, ok := t.maximum(); ok {
+		if exclusive && n >= max {
+			v.fail(instancePath, schemaPath, boundKeyword("maximum", t.ExclusiveMaximum), "%s is not less than %s", formatNumber(n), formatNumber(max))
+		} else if n > max {
+			v.fail(instancePath, schemaPath, "maximum", "%s is greater than %s", formatNumber(n), formatNumber(max))
+		}
+	}
+	if min, exclusive, ok := t.minimum(); ok {
+		if exclusive && n <= min {
+			v.fail(instancePath, schemaPath, boundKeyword("minimum", t.ExclusiveMinimum), "%s is not greater than %s", formatNumber(n), formatNumber(min))
+		} else if n < min {
+			v.fail(instancePath, schemaPath, "minimum", "%s is less than %s", formatNumber(n), formatNumber(min))
+		}
+	}
+}
+
+// validateString checks the string keywords.
+// RFC draft-handrews-json-schema-validation-01, section 6.3
+func (v *validator) validateString(t *Type, s, instancePath, schemaPath string) {
+	length := utf8.RuneCountInString(s)
+	if t.MaxLength != nil && length > *t.MaxLength {
+		v.fail(instancePath, schemaPath, "maxLength", "string is longer than %d characters", *t.MaxLength)
+	}
+	if t.MinLength > 0 && length < t.MinLength {
+		v.fail(instancePath, schemaPath, "minLength", "string is shorter than %d characters", t.MinLength)
+	}
+	if t.Pattern != "" {
+		re, err := compilePattern(t.Pattern)
+		if err != nil {
+			v.fail(instancePath, schemaPath, "pattern", "invalid pattern %q: %s", t.Pattern, err)
+		} else if !re.MatchString(s) {
+			v.fail(instancePath, schemaPath, "pattern", "string does not match pattern %q", t.Pattern)
+		}
+	}
+	if t.Format != "" {
+		check, ok := lookupFormat(t.Format)
+		if !ok && v.resolver.StrictFormats {
+			v.fail(instancePath, schemaPath, "format", "unknown format %q", t.Format)
+		} else if ok && !check(s) {
+			v.fail(instancePath, schemaPath, "format", "%q is not a valid %s", s, t.Format)
+		}
+	}
+}
+
+// validateArray checks the array keywords.
+// RFC draft-handrews-json-schema-validation-01, section 6.4
+func (v *validator) validateArray(t *Type, items []interface{}, instancePath, schemaPath string) {
+	v.validateItemCount(t, len(items), instancePath, schemaPath)
+	if t.UniqueItems {
+		seen := map[string]int{}
+		for i, item := range items {
+			key := canonicalJSON(item)
+			if j, ok := seen[key]; ok {
+				v.fail(instancePath, schemaPath, "uniqueItems", "items %d and %d are equal", j, i)
+				break
+			}
+			seen[key] = i
+		}
+	}
+
+	for i, item := range items {
+		if sub, subPath := itemSchema(t, i, schemaPath); sub != nil {
+			v.validate(sub, item, instancePath+"/"+strconv.Itoa(i), subPath)
+		}
+	}
+
+	if t.Contains != nil {
+		found := false
+		for _, item := range items {
+			if v.valid(t.Contains, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(instancePath, schemaPath, "contains", "no item matches the contains schema")
+		}
+	}
+}
+
+// validateObject checks the object keywords.
+// RFC draft-handrews-json-schema-validation-01, section 6.5
+func (v *validator) validateObject(t *Type, object map[string]interface{}, instancePath, schemaPath string) {
+	has := func(name string) bool {
+		_, ok := object[name]
+		return ok
+	}
+	v.validatePropertyCount(t, len(object), has, instancePath, schemaPath)
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertyPath := instancePath + "/" + escapePointerToken(name)
+		for _, sub := range v.propertySchemas(t, name, instancePath, schemaPath, nil) {
+			v.validate(sub.t, object[name], propertyPath, sub.path)
+		}
+	}
+
+	for _, name := range sortedKeys(t.Dependencies) {
+		if has(name) {
+			v.validate(t.Dependencies[name], object, instancePath, schemaPath+"/dependencies/"+escapePointerToken(name))
+		}
+	}
+	v.validateDependentRequired(t, has, instancePath, schemaPath)
+}
+
+// validateItemCount checks the maxItems and minItems keywords for an array of
+// n items.
+func (v *validator) validateItemCount(t *Type, n int, instancePath, schemaPath string) {
+	if t.MaxItems != nil && n > *t.MaxItems {
+		v.fail(instancePath, schemaPath, "maxItems", "array has more than %d items", *t.MaxItems)
+	}
+	if t.MinItems > 0 && n < t.MinItems {
+		v.fail(instancePath, schemaPath, "minItems", "array has fewer than %d items", t.MinItems)
+	}
+}
+
+// itemSchema returns the schema of item i of an array, and its path, or nil
+// if the item is not constrained.
+func itemSchema(t *Type, i int, schemaPath string) (*Type, string) {
+	switch {
+	case t.TupleItems != nil:
+		if i < len(t.TupleItems) {
+			return t.TupleItems[i], schemaPath + "/items/" + strconv.Itoa(i)
+		}
+		return t.AdditionalItems, schemaPath + "/additionalItems"
+	case t.Items != nil:
+		return t.Items, schemaPath + "/items"
+	}
+	return nil, ""
+}
+
+// validatePropertyCount checks the maxProperties, minProperties and required
+// keywords for an object of n properties, for which has reports whether a
+// property is present.
+func (v *validator) validatePropertyCount(t *Type, n int, has func(string) bool, instancePath, schemaPath string) {
+	if t.MaxProperties != nil && n > *t.MaxProperties {
+		v.fail(instancePath, schemaPath, "maxProperties", "object has more than %d properties", *t.MaxProperties)
+	}
+	if t.MinProperties > 0 && n < t.MinProperties {
+		v.fail(instancePath, schemaPath, "minProperties", "object has fewer than %d properties", t.MinProperties)
+	}
+	for _, name := range t.Required {
+		if !has(name) {
+			v.fail(instancePath, schemaPath, "required", "missing required property %q", name)
+		}
+	}
+}
+
+// validateDependentRequired checks the array form of the dependencies
+// keyword.
+func (v *validator) validateDependentRequired(t *Type, has func(string) bool, instancePath, schemaPath string) {
+	for _, name := range sortedStringKeys(t.DependentRequired) {
+		if !has(name) {
+			continue
+		}
+		for _, dependency := range t.DependentRequired[name] {
+			if !has(dependency) {
+				v.fail(instancePath, schemaPath, "dependencies", "property %q requires property %q", name, dependency)
+			}
+		}
+	}
+}
+
+// schemaAt is a schema applied to a value, with its path.
+type schemaAt struct {
+	t    *Type
+	path string
+}
+
+// propertySchemas appends to schemas the schemas that t applies to the value
+// of its property name: those of properties, matching patternProperties and
+// otherwise additionalProperties. The property name itself is checked against
+// additionalProperties false and propertyNames.
+func (v *validator) propertySchemas(t *Type, name, instancePath, schemaPath string, schemas []schemaAt) []schemaAt {
+	propertyPath := instancePath + "/" + escapePointerToken(name)
+	matched := false
+	if sub, ok := t.Properties.Get(name); ok {
+		matched = true
+		schemas = append(schemas, schemaAt{sub, schemaPath + "/properties/" + escapePointerToken(name)})
+	}
+	for _, pattern := range sortedKeys(t.PatternProperties) {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			v.fail(instancePath, schemaPath, "patternProperties", "invalid pattern %q: %s", pattern, err)
+			continue
+		}
+		if re.MatchString(name) {
+			matched = true
+			schemas = append(schemas, schemaAt{t.PatternProperties[pattern], schemaPath + "/patternProperties/" + escapePointerToken(pattern)})
+		}
+	}
+	if !matched && t.AdditionalProperties != nil {
+		if allowed, ok := t.AdditionalProperties.Boolean(); ok && !allowed {
+			v.fail(propertyPath, schemaPath, "additionalProperties", "property %q is not allowed", name)
+		} else {
+			schemas = append(schemas, schemaAt{t.AdditionalProperties, schemaPath + "/additionalProperties"})
+		}
+	}
+	if t.PropertyNames != nil && !v.valid(t.PropertyNames, name) {
+		v.fail(propertyPath, schemaPath, "propertyNames", "property name %q is not valid", name)
+	}
+	return schemas
+}
+
+// validateCombinators checks the keywords that apply subschemas to the value
+// as a whole.
+// RFC draft-handrews-json-schema-validation-01, section 6.6 and 6.7
+func (v *validator) validateCombinators(t *Type, instance interface{}, instancePath, schemaPath string) {
+	for i, sub := range t.AllOf {
+		v.validate(sub, instance, instancePath, schemaPath+"/allOf/"+strconv.Itoa(i))
+	}
+	if len(t.AnyOf) > 0 {
+		matched := false
+		for _, sub := range t.AnyOf {
+			if v.valid(sub, instance) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(instancePath, schemaPath, "anyOf", "value does not match any of the anyOf schemas")
+		}
+	}
+	if len(t.OneOf) > 0 {
+		var matches []string
+		for i, sub := range t.OneOf {
+			if v.valid(sub, instance) {
+				matches = append(matches, strconv.Itoa(i))
+			}
+		}
+		switch len(matches) {
+		case 0:
+			v.fail(instancePath, schemaPath, "oneOf", "value does not match any of the oneOf schemas")
+		case 1:
+		default:
+			v.fail(instancePath, schemaPath, "oneOf", "value matches more than one of the oneOf schemas: %s", strings.Join(matches, ", "))
+		}
+	}
+	if t.Not != nil && v.valid(t.Not, instance) {
+		v.fail(instancePath, schemaPath, "not", "value must not match the not schema")
+	}
+	if t.If != nil {
+		if v.valid(t.If, instance) {
+			if t.Then != nil {
+				v.validate(t.Then, instance, instancePath, schemaPath+"/then")
+			}
+		} else if t.Else != nil {
+			v.validate(t.Else, instance, instancePath, schemaPath+"/else")
+		}
+	}
+}
+
+// jsonType returns the JSON type of a decoded value, with "integer" for
+// numbers without a fractional part.
+func jsonType(instance interface{}) string {
+	switch value := instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case array:
+		return "array"
+	case object:
+		return "object"
+	default:
+		if n, ok := toFloat(value); ok {
+			if n == math.Trunc(n) && !math.IsInf(n, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+	}
+	return fmt.Sprintf("%T", instance)
+}
+
+// isMultipleOf reports whether instance is a multiple of divisor, computed
+// exactly from their decimal representations, so that 0.3 is a multiple of
+// 0.1.
+func isMultipleOf(instance interface{}, divisor json.Number) bool {
+	return isMultipleOfRat(instance, parseDivisor(divisor))
+}
+
+// parseDivisor returns the value of a multipleOf keyword, or nil if it is not
+// a valid divisor.
+func parseDivisor(divisor json.Number) *big.Rat {
+	d, ok := new(big.Rat).SetString(divisor.String())
+	if !ok || d.Sign() == 0 {
+		return nil
+	}
+	return d
+}
+
+// isMultipleOfRat reports whether instance is a multiple of d, which is not
+// checked if it is nil.
+func isMultipleOfRat(instance interface{}, d *big.Rat) bool {
+	if d == nil {
+		return true
+	}
+	decimal, isNumber := instance.(json.Number)
+	if !isNumber {
+		f, _ := toFloat(instance)
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return false
+		}
+		decimal = json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	n, ok := new(big.Rat).SetString(decimal.String())
+	return ok && new(big.Rat).Quo(n, d).IsInt()
+}
+
+// boundKeyword returns the keyword that set an exclusive bound: the draft-06
+// exclusive keyword if it is a number, or else the inclusive keyword that a
+// draft-04 boolean applies to.
+func boundKeyword(keyword string, exclusive []byte) string {
+	s := strings.TrimSpace(string(exclusive))
+	if s == "" || s == "true" || s == "false" {
+		return keyword
+	}
+	return "exclusive" + strings.ToUpper(keyword[:1]) + keyword[1:]
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
+
+var patterns sync.Map // map[string]*regexp.Regexp
+
+// compilePattern compiles a pattern of a schema, caching the result.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,113 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// validateTests are instances that fail, or pass, a schema, with the
+// failures expected.
+var validateTests = []struct {
+	schema   string
+	instance string
+	errors   []string
+}{
+	{`true`, `{"a": 1}`, nil},
+	{`false`, `1`, []string{"/: no value is allowed"}},
+	{`{"type": "integer"}`, `1.0`, nil},
+	{`{"type": "integer"}`, `1.5`, []string{"/: expected integer, but got number"}},
+	{`{"type": ["string", "null"]}`, `true`, []string{"/: expected string or null, but got boolean"}},
+	{`{"enum": ["a", {"b": [1]}]}`, `{"b": [1.0]}`, nil},
+	{`{"enum": ["a", 1]}`, `"b"`, []string{`/: value must be one of "a", 1`}},
+	{`{"const": 2}`, `3`, []string{"/: value must be 2"}},
+	{`{"const": null}`, `0`, []string{"/: value must be null"}},
+	{`{"const": null}`, `null`, nil},
+	{`{"multipleOf": 0.1}`, `0.3`, nil},
+	{`{"multipleOf": 2}`, `7`, []string{"/: 7 is not a multiple of 2"}},
+	{`{"maximum": 10, "exclusiveMaximum": true}`, `10`, []string{"/: 10 is not less than 10"}},
+	{`{"minimum": 1, "exclusiveMinimum": 0}`, `0`, []string{"/: 0 is less than 1"}},
+	{`{"exclusiveMinimum": 0}`, `0`, []string{"/: 0 is not greater than 0"}},
+	{`{"maxLength": 2}`, `"日本"`, nil},
+	{`{"maxLength": 0}`, `"a"`, []string{"/: string is longer than 0 characters"}},
+	{`{"maxItems": 0, "maxProperties": 0}`, `[1]`, []string{"/: array has more than 0 items"}},
+	{`{"maxItems": 0, "maxProperties": 0}`, `{"a": 1}`, []string{"/: object has more than 0 properties"}},
+	{`{"minLength": 3, "pattern": "^a"}`, `"ba"`, []string{
+		"/: string is shorter than 3 characters",
+		`/: string does not match pattern "^a"`,
+	}},
+	{`{"format": "date-time"}`, `"2020-01-02T03:04:05Z"`, nil},
+	{`{"format": "email"}`, `"nobody"`, []string{`/: "nobody" is not a valid email`}},
+	{`{"format": "unknown"}`, `"anything"`, nil},
+	{`{"items": {"type": "string"}, "maxItems": 1}`, `["a", 2]`, []string{
+		"/: array has more than 1 items",
+		"/1: expected string, but got integer",
+	}},
+	{`{"items": [{"type": "string"}], "additionalItems": false}`, `["a", 2]`, []string{"/1: no value is allowed"}},
+	{`{"uniqueItems": true}`, `[1, {"a": 1}, {"a": 1.0}]`, []string{"/: items 1 and 2 are equal"}},
+	{`{"contains": {"const": 1}}`, `[2, 3]`, []string{"/: no item matches the contains schema"}},
+	{`{"required": ["a"], "properties": {"b": {"type": "string"}}, "additionalProperties": false}`, `{"b": 1, "c~/": 2}`, []string{
+		`/: missing required property "a"`,
+		"/b: expected string, but got integer",
+		`/c~0~1: property "c~/" is not allowed`,
+	}},
+	{`{"patternProperties": {"^x-": {"type": "integer"}}, "additionalProperties": {"type": "string"}}`, `{"x-a": 1, "b": "c"}`, nil},
+	{`{"propertyNames": {"maxLength": 1}}`, `{"ab": 1}`, []string{`/ab: property name "ab" is not valid`}},
+	{`{"dependencies": {"a": ["b"], "c": {"required": ["d"]}}}`, `{"a": 1, "c": 2}`, []string{
+		`/: missing required property "d"`,
+		`/: property "a" requires property "b"`,
+	}},
+	{`{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, []string{"/: value does not match any of the anyOf schemas"}},
+	{`{"oneOf": [{"minimum": 1}, {"maximum": 5}]}`, `3`, []string{"/: value matches more than one of the oneOf schemas: 0, 1"}},
+	{`{"not": {"type": "null"}}`, `null`, []string{"/: value must not match the not schema"}},
+	{`{"if": {"minimum": 10}, "then": {"multipleOf": 10}, "else": {"const": 1}}`, `15`, []string{"/: 15 is not a multiple of 10"}},
+	{`{"definitions": {"a": {"type": "string"}}, "properties": {"x": {"$ref": "#/definitions/a", "type": "integer"}}}`, `{"x": "y"}`, nil},
+	{`{"properties": {"x": {"$ref": "other.json"}}}`, `{"x": 1}`, []string{`/x: jsonschema: no schema for "other.json"`}},
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range validateTests {
+		t.Run(test.schema+" "+test.instance, func(t *testing.T) {
+			s := mustSchema(t, test.schema)
+			err := s.ValidateJSON([]byte(test.instance))
+			if test.errors == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.IsType(t, ValidationErrors{}, err)
+			var actual []string
+			for _, e := range err.(ValidationErrors) {
+				actual = append(actual, e.Error())
+			}
+			require.Equal(t, test.errors, actual)
+		})
+	}
+}
+
+func TestValidateSchemaPath(t *testing.T) {
+	s := mustSchema(t, `{
+		"definitions": {"name": {"type": "string", "maxLength": 3}},
+		"properties": {"names": {"items": {"$ref": "#/definitions/name"}}}
+	}`)
+	err := s.Validate(map[string]interface{}{"names": []interface{}{"abcd"}})
+	require.Equal(t, ValidationErrors{{
+		InstancePath: "/names/0",
+		SchemaPath:   "/properties/names/items/$ref/maxLength",
+		Keyword:      "maxLength",
+		Message:      "string is longer than 3 characters",
+	}}, err)
+}
+
+func TestValidateWithResolver(t *testing.T) {
+	r := NewResolver(MemoryLoader{
+		"http://example.com/common.json": mustSchema(t, `{"definitions": {"id": {"type": "integer"}}}`),
+	})
+	s := mustSchema(t, `{"$id": "http://example.com/order.json", "properties": {"id": {"$ref": "common.json#/definitions/id"}}}`)
+	require.NoError(t, r.AddSchema("", s))
+
+	var instance interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"id": 1}`), &instance))
+	require.NoError(t, r.Validate(s.Type, instance))
+	require.EqualError(t, r.Validate(s.Type, map[string]interface{}{"id": "1"}), "/id: expected integer, but got string")
+}
@@ -0,0 +1,143 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// toFloat returns the value of a JSON number, whether it was decoded as a
+// json.Number or a float64, or given as any other Go number.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// canonicalJSON returns an encoding of the JSON value v in which object keys
+// are sorted and numbers are formatted alike whatever their Go type, so that
+// equal JSON values have equal encodings.
+func canonicalJSON(v interface{}) string {
+	b := &strings.Builder{}
+	writeCanonicalJSON(b, v)
+	return b.String()
+}
+
+func writeCanonicalJSON(b *strings.Builder, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case string:
+		s, _ := json.Marshal(v)
+		b.Write(s)
+	case []interface{}:
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonicalJSON(b, item)
+		}
+		b.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonicalJSON(b, key)
+			b.WriteByte(':')
+			writeCanonicalJSON(b, v[key])
+		}
+		b.WriteByte('}')
+	default:
+		if f, ok := toFloat(v); ok {
+			b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+			return
+		}
+		// Any other Go value is compared by its JSON encoding.
+		data, err := json.Marshal(v)
+		if err != nil {
+			b.WriteString(err.Error())
+			return
+		}
+		var decoded interface{}
+		if err := decodeJSON(data, &decoded); err != nil {
+			b.Write(data)
+			return
+		}
+		writeCanonicalJSON(b, decoded)
+	}
+}
+
+// equalJSON reports whether a and b are equal JSON values.
+func equalJSON(a, b interface{}) bool {
+	return canonicalJSON(a) == canonicalJSON(b)
+}
+
+// maximum returns the upper bound set by maximum and exclusiveMaximum, in
+// either their draft-04 or draft-06 form.
+func (t *Type) maximum() (value float64, exclusive bool, ok bool) {
+	return combineBounds(t.Maximum, t.ExclusiveMaximum, func(a, b float64) bool { return a < b })
+}
+
+// maxCount returns the value of a maxLength, maxItems or maxProperties
+// keyword, or -1 if it is absent.
+func maxCount(max *int) int {
+	if max == nil {
+		return -1
+	}
+	return *max
+}
+
+// minimum returns the lower bound set by minimum and exclusiveMinimum, in
+// either their draft-04 or draft-06 form.
+func (t *Type) minimum() (value float64, exclusive bool, ok bool) {
+	return combineBounds(t.Minimum, t.ExclusiveMinimum, func(a, b float64) bool { return a > b })
+}
+
+// combineBounds combines an inclusive bound with an exclusive one, which is either a
+// draft-04 boolean applying to the inclusive bound or a draft-06 number.
+// tighter reports whether a is a tighter bound than b.
+func combineBounds(inclusive json.Number, exclusive json.RawMessage, tighter func(a, b float64) bool) (float64, bool, bool) {
+	value, err := inclusive.Float64()
+	ok := err == nil
+	isExclusive := false
+	switch s := strings.TrimSpace(string(exclusive)); s {
+	case "", "false":
+	case "true":
+		isExclusive = ok
+	default:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			if !ok || tighter(n, value) || n == value {
+				value, isExclusive, ok = n, true, true
+			}
+		}
+	}
+	return value, isExclusive, ok
+}
@@ -0,0 +1,216 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// eachSubschema calls fn for every direct subschema of t, passing the JSON
+// Pointer tokens that lead from t to the subschema. Subschemas are visited in
+// a stable order: properties in order, and other maps in key order.
+func (t *Type) eachSubschema(fn func(tokens []string, sub *Type)) {
+	t.replaceSubschemas(func(tokens []string, sub *Type) *Type {
+		fn(tokens, sub)
+		return sub
+	})
+}
+
+// replaceSubschemas replaces every direct subschema of t with the result of
+// calling fn on it, visiting them in the same order as eachSubschema. The
+// containers of t are only written to when fn returns a different schema.
+func (t *Type) replaceSubschemas(fn func(tokens []string, sub *Type) *Type) {
+	one := func(keyword string, sub **Type) {
+		if *sub != nil {
+			if r := fn([]string{keyword}, *sub); r != *sub {
+				*sub = r
+			}
+		}
+	}
+	list := func(keyword string, subs []*Type) {
+		for i, sub := range subs {
+			if sub != nil {
+				if r := fn([]string{keyword, strconv.Itoa(i)}, sub); r != sub {
+					subs[i] = r
+				}
+			}
+		}
+	}
+	named := func(keyword string, subs map[string]*Type) {
+		for _, name := range sortedKeys(subs) {
+			if sub := subs[name]; sub != nil {
+				if r := fn([]string{keyword, name}, sub); r != sub {
+					subs[name] = r
+				}
+			}
+		}
+	}
+
+	one("additionalItems", &t.AdditionalItems)
+	one("items", &t.Items)
+	list("items", t.TupleItems)
+	one("contains", &t.Contains)
+	for _, name := range t.Properties.Keys() {
+		if sub, _ := t.Properties.Get(name); sub != nil {
+			if r := fn([]string{"properties", name}, sub); r != sub {
+				t.Properties.Set(name, r)
+			}
+		}
+	}
+	named("patternProperties", t.PatternProperties)
+	one("additionalProperties", &t.AdditionalProperties)
+	named("dependencies", t.Dependencies)
+	one("propertyNames", &t.PropertyNames)
+	list("allOf", t.AllOf)
+	list("anyOf", t.AnyOf)
+	list("oneOf", t.OneOf)
+	one("not", &t.Not)
+	one("if", &t.If)
+	one("then", &t.Then)
+	one("else", &t.Else)
+	named("definitions", t.Definitions)
+	one("media", &t.Media)
+}
+
+// clone returns a deep copy of t. If fn is not nil it is called with every
+// schema in t and its copy, parents before children.
+func (t *Type) clone(fn func(orig, copy *Type)) *Type {
+	c := *t
+	c.ExclusiveMaximum = append(json.RawMessage(nil), t.ExclusiveMaximum...)
+	c.ExclusiveMinimum = append(json.RawMessage(nil), t.ExclusiveMinimum...)
+	c.Required = append([]string(nil), t.Required...)
+	c.Enum = append([]interface{}(nil), t.Enum...)
+	c.Types = append([]string(nil), t.Types...)
+	c.Examples = append([]interface{}(nil), t.Examples...)
+	if t.TupleItems != nil {
+		c.TupleItems = append([]*Type{}, t.TupleItems...)
+	}
+	c.AllOf = append([]*Type(nil), t.AllOf...)
+	c.AnyOf = append([]*Type(nil), t.AnyOf...)
+	c.OneOf = append([]*Type(nil), t.OneOf...)
+	c.PatternProperties = copySchemaMap(t.PatternProperties)
+	c.Dependencies = copySchemaMap(t.Dependencies)
+	c.Definitions = Definitions(copySchemaMap(t.Definitions))
+	if t.Properties != nil {
+		c.Properties = NewProperties()
+		for _, name := range t.Properties.Keys() {
+			sub, _ := t.Properties.Get(name)
+			c.Properties.Set(name, sub)
+		}
+	}
+	if t.DependentRequired != nil {
+		c.DependentRequired = map[string][]string{}
+		for name, required := range t.DependentRequired {
+			c.DependentRequired[name] = append([]string(nil), required...)
+		}
+	}
+	if t.Extras != nil {
+		c.Extras = map[string]interface{}{}
+		for key, value := range t.Extras {
+			c.Extras[key] = value
+		}
+	}
+	if fn != nil {
+		fn(t, &c)
+	}
+	c.replaceSubschemas(func(_ []string, sub *Type) *Type {
+		return sub.clone(fn)
+	})
+	return &c
+}
+
+func copySchemaMap(m map[string]*Type) map[string]*Type {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]*Type, len(m))
+	for name, sub := range m {
+		c[name] = sub
+	}
+	return c
+}
+
+// subschema returns the direct subschema of t at the given JSON Pointer
+// tokens, and the number of tokens used to reach it.
+func (t *Type) subschema(tokens []string) (*Type, int) {
+	if len(tokens) == 0 {
+		return nil, 0
+	}
+	index := func(subs []*Type) (*Type, int) {
+		if len(tokens) < 2 {
+			return nil, 0
+		}
+		i, err := strconv.Atoi(tokens[1])
+		if err != nil || i < 0 || i >= len(subs) {
+			return nil, 0
+		}
+		return subs[i], 2
+	}
+	key := func(subs map[string]*Type) (*Type, int) {
+		if len(tokens) < 2 || subs[tokens[1]] == nil {
+			return nil, 0
+		}
+		return subs[tokens[1]], 2
+	}
+	one := func(sub *Type) (*Type, int) {
+		if sub == nil {
+			return nil, 0
+		}
+		return sub, 1
+	}
+
+	switch tokens[0] {
+	case "additionalItems":
+		return one(t.AdditionalItems)
+	case "items":
+		if t.TupleItems != nil {
+			return index(t.TupleItems)
+		}
+		return one(t.Items)
+	case "contains":
+		return one(t.Contains)
+	case "properties":
+		if len(tokens) < 2 {
+			return nil, 0
+		}
+		if sub, ok := t.Properties.Get(tokens[1]); ok {
+			return sub, 2
+		}
+	case "patternProperties":
+		return key(t.PatternProperties)
+	case "additionalProperties":
+		return one(t.AdditionalProperties)
+	case "dependencies":
+		return key(t.Dependencies)
+	case "propertyNames":
+		return one(t.PropertyNames)
+	case "allOf":
+		return index(t.AllOf)
+	case "anyOf":
+		return index(t.AnyOf)
+	case "oneOf":
+		return index(t.OneOf)
+	case "not":
+		return one(t.Not)
+	case "if":
+		return one(t.If)
+	case "then":
+		return one(t.Then)
+	case "else":
+		return one(t.Else)
+	case "definitions":
+		return key(t.Definitions)
+	case "media":
+		return one(t.Media)
+	}
+	return nil, 0
+}
+
+func sortedKeys(m map[string]*Type) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
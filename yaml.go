@@ -0,0 +1,197 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAML implements yaml.Marshaler.
+//
+// Keys are in the same stable order as in MarshalJSON: keywords in a fixed
+// order, properties in order, and other maps and Extras in key order.
+func (s Schema) MarshalYAML() (interface{}, error) {
+	data, err := s.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAML(data)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+//
+// The definitions of the root schema are moved to Schema.Definitions, as in
+// UnmarshalJSON.
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	data, err := yamlToJSON(node)
+	if err != nil {
+		return err
+	}
+	return s.UnmarshalJSON(data)
+}
+
+// MarshalYAML implements yaml.Marshaler, with keys in the order of
+// MarshalJSON.
+func (t Type) MarshalYAML() (interface{}, error) {
+	data, err := t.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAML(data)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *Type) UnmarshalYAML(node *yaml.Node) error {
+	data, err := yamlToJSON(node)
+	if err != nil {
+		return err
+	}
+	return t.UnmarshalJSON(data)
+}
+
+// jsonToYAML returns the YAML node of the JSON document data, keeping the
+// order of the keys of its objects.
+func jsonToYAML(data []byte) (*yaml.Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	return yamlNode(dec, tok)
+}
+
+// yamlNode returns the YAML node of the JSON value starting with tok, reading
+// the rest of it from the decoder.
+func yamlNode(dec *json.Decoder, tok json.Token) (*yaml.Node, error) {
+	switch value := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if value == '{' {
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if value == '{' {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tok.(string)})
+				if tok, err = dec.Token(); err != nil {
+					return nil, err
+				}
+			}
+			child, err := yamlNode(dec, tok)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		_, err := dec.Token()
+		return node, err
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(value.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(value)}, nil
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+}
+
+// yamlToJSON returns the JSON encoding of the YAML node, keeping the order of
+// the keys of its mappings, which must be strings.
+func yamlToJSON(node *yaml.Node) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeYAMLAsJSON(buf, node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeYAMLAsJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeYAMLAsJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeYAMLAsJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode || key.ShortTag() != "!!str" {
+				return fmt.Errorf("jsonschema: line %d: key %q is not a string", key.Line, key.Value)
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			name, _ := json.Marshal(key.Value)
+			buf.Write(name)
+			buf.WriteByte(':')
+			if err := writeYAMLAsJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeYAMLAsJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	// Numbers are kept as written when they are valid JSON.
+	tag := node.ShortTag()
+	if (tag == "!!int" || tag == "!!float") && json.Valid([]byte(node.Value)) {
+		buf.WriteString(node.Value)
+		return nil
+	}
+	var value interface{} = node.Value
+	if tag != "!!str" && tag != "!!timestamp" {
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("jsonschema: line %d: %s", node.Line, err)
+	}
+	buf.Write(data)
+	return nil
+}
+
+// EncodeYAML returns the YAML encoding of v, indented by two spaces, as YAML
+// is written by the generators of this package and the jsonschema command. A
+// Schema or Type keeps the key order of MarshalJSON.
+func EncodeYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
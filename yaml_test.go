@@ -0,0 +1,121 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchemaYAML(t *testing.T) {
+	s := Reflect(&TestUser{})
+	actual, err := yaml.Marshal(s)
+	require.NoError(t, err)
+	expected, err := ioutil.ReadFile("fixtures/test_user.yaml.golden")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+
+	// Marshaling is stable, and the schema reads back as it was.
+	again, err := yaml.Marshal(s)
+	require.NoError(t, err)
+	require.Equal(t, string(actual), string(again))
+	read := &Schema{}
+	require.NoError(t, yaml.Unmarshal(actual, read))
+	expectedJSON, err := json.Marshal(s)
+	require.NoError(t, err)
+	actualJSON, err := json.Marshal(read)
+	require.NoError(t, err)
+	require.Equal(t, string(expectedJSON), string(actualJSON))
+}
+
+func TestSchemaYAMLScalars(t *testing.T) {
+	s := mustSchema(t, `{
+		"enum": ["true", "1", "null", "yes", "2001-12-14", "", "a: b", 1, 1.5, 1e3, true, null],
+		"description": "first line\nsecond line",
+		"items": false
+	}`)
+	data, err := yaml.Marshal(s)
+	require.NoError(t, err)
+	read := &Schema{}
+	require.NoError(t, yaml.Unmarshal(data, read))
+	expected, err := json.Marshal(s)
+	require.NoError(t, err)
+	actual, err := json.Marshal(read)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+
+	err = yaml.Unmarshal([]byte("properties:\n  1: {type: string}\n"), &Schema{})
+	require.EqualError(t, err, `jsonschema: line 2: key "1" is not a string`)
+}
+
+type YAMLConfig struct {
+	Name     string            `yaml:"name" json:"ignored"`
+	Replicas int               `yaml:",omitempty"`
+	Server   YAMLServer        `yaml:",inline"`
+	Extra    map[string]string `yaml:",inline"`
+	Tags     []string          `yaml:"tags,flow"`
+	Skipped  string            `yaml:"-"`
+	YAMLMeta
+}
+
+type YAMLServer struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port,omitempty" jsonschema:"minimum=1"`
+}
+
+type YAMLMeta struct {
+	Owner string `yaml:"owner"`
+}
+
+func TestPreferYAMLTags(t *testing.T) {
+	r := &Reflector{PreferYAMLTags: true, ExpandedStruct: true}
+	s := r.Reflect(&YAMLConfig{})
+	actual, err := json.Marshal(s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"replicas": {"type": "integer"},
+			"host": {"type": "string"},
+			"port": {"type": "integer", "minimum": 1},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"yamlmeta": {"$schema": "http://json-schema.org/draft-04/schema#", "$ref": "#/definitions/YAMLMeta"}
+		},
+		"required": ["name", "host", "tags", "yamlmeta"],
+		"additionalProperties": {"type": "string"},
+		"definitions": {
+			"YAMLMeta": {
+				"type": "object",
+				"properties": {"owner": {"type": "string"}},
+				"required": ["owner"],
+				"additionalProperties": false
+			}
+		}
+	}`, string(actual))
+
+	// The schema describes the documents yaml.v3 encodes.
+	config := &YAMLConfig{
+		Name:   "api",
+		Server: YAMLServer{Host: "localhost", Port: 8080},
+		Extra:  map[string]string{"region": "eu"},
+		Tags:   []string{"a"},
+		YAMLMeta: YAMLMeta{
+			Owner: "ops",
+		},
+	}
+	data, err := yaml.Marshal(config)
+	require.NoError(t, err)
+	var document interface{}
+	require.NoError(t, yaml.Unmarshal(data, &document))
+	require.NoError(t, s.Validate(document))
+	require.NoError(t, r.ValidateValue(config))
+
+	config.Server.Port = -1
+	err = r.ValidateValue(config)
+	require.EqualError(t, err, "/port: -1 is less than 1")
+	require.Equal(t, "YAMLConfig.Server.Port", err.(ValidationErrors)[0].GoPath)
+}